@@ -1,9 +1,12 @@
 package dataq
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,14 +21,41 @@ const (
 type QData struct {
 	db           QInterface
 	dbName       string
+	ctx          context.Context
 	tx           *sql.Tx
+	savepoint    string
+	txSeq        *int64
+	err          error
 	preparedStmt *sync.Map
 	shared       *sharedConfig
+	middlewares  []QMiddleware
 	config       Config
 }
 
 type Config struct {
-	DebugLvl        int
+	// DebugLvl prints the statements to stdout if no Logger is set
+	DebugLvl int
+	// Logger receives an event per statement
+	Logger Logger
+	// RedactArgs replaces the bound values of the events with RedactedArg
+	RedactArgs bool
+	// Clock returns the time of the `AUTOCREATE` and `AUTOUPDATE` fields, time.Now if nil
+	Clock func() time.Time
+	// Location of the `AUTOCREATE` and `AUTOUPDATE` fields, the location of Clock if nil
+	Location *time.Location
+	// LooseDecode sets the field to its zero value instead of returning the error
+	// when the column can not be decoded
+	LooseDecode bool
+	// Dialect of the database, MySQL by default
+	Dialect Dialect
+	// Driver is the driver name for sql.Open(), Dialect.DriverName() by default
+	Driver string
+	// Naming names the tables and the columns without `TABLE` / `COL`, the Go names by default
+	Naming *NamingStrategy
+	// TxMaxRetries is the max retries of Transact() on deadlocks, DefaultTxMaxRetries if 0, no retry if negative
+	TxMaxRetries int
+	// TxRetryBackoff is the first backoff of the retries, DefaultTxRetryBackoff if 0
+	TxRetryBackoff  time.Duration
 	ConnMaxIdleTime time.Duration
 	ConnMaxLifetime time.Duration
 	MaxIdleConns    int
@@ -42,12 +72,13 @@ type dConnCloseI interface {
 	Close() error
 }
 
-// Open MySQL Connection
+// Open the database connection, MySQL unless Config.Dialect is set
 // Open(connectionString, debugLevel)
+// Open(connectionString, Config)
 func Open(args ...any) (dbc *QData, err error) {
 	lenArgs := len(args)
 	if lenArgs == 0 {
-		err = errors.New("dataq: invalid database source")
+		return nil, ErrInvalidSource
 	}
 	var (
 		connectString string
@@ -57,12 +88,6 @@ func Open(args ...any) (dbc *QData, err error) {
 		ok            bool
 	)
 
-	switch value := args[0].(type) {
-	case string:
-		connectString = value
-		dbConn, err = sql.Open("mysql", connectString)
-	}
-
 	if lenArgs == 2 {
 		// TODO: need more params
 		if _, ok = args[1].(float64); ok {
@@ -72,6 +97,24 @@ func Open(args ...any) (dbc *QData, err error) {
 		}
 	}
 
+	if config.Dialect == nil {
+		config.Dialect = MySQL
+	}
+	if config.Driver == "" {
+		config.Driver = config.Dialect.DriverName()
+	}
+
+	switch value := args[0].(type) {
+	case string:
+		connectString = value
+		dbConn, err = sql.Open(config.Driver, connectString)
+	default:
+		return nil, ErrInvalidSource
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	if config.ConnMaxIdleTime == 0 {
 		config.ConnMaxIdleTime = DefaultConnMaxIdleTime
 	}
@@ -96,7 +139,7 @@ func Open(args ...any) (dbc *QData, err error) {
 		d.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 	}
 
-	dbConn.QueryRow("SELECT DATABASE()").Scan(&dbName)
+	dbConn.QueryRow(config.Dialect.CurrentDatabase()).Scan(&dbName)
 	dbc = &QData{
 		db:     dbConn,
 		dbName: dbName,
@@ -130,18 +173,39 @@ func (dbc *QData) clone() *QData {
 	newc := QData{
 		db:           dbc.db,
 		dbName:       dbc.dbName,
+		ctx:          dbc.ctx,
 		shared:       dbc.shared,
+		middlewares:  dbc.middlewares,
 		config:       dbc.config,
 		preparedStmt: &sync.Map{},
 	}
 
 	if dbc.tx != nil {
 		newc.tx = dbc.tx
+		newc.txSeq = dbc.txSeq
 	}
 
 	return &newc
 }
 
+// WithContext returns a copy of the connection bound to ctx,
+// every statement issued through the copy honours its cancellation and deadline
+func (dbc *QData) WithContext(ctx context.Context) *QData {
+	newc := dbc.clone()
+	newc.ctx = ctx
+
+	return newc
+}
+
+// Context returns the bound context, context.Background() if none
+func (dbc *QData) Context() context.Context {
+	if dbc.ctx != nil {
+		return dbc.ctx
+	}
+
+	return context.Background()
+}
+
 // DBName return Name of Database
 func (dbc *QData) DBName() string {
 	return dbc.dbName
@@ -157,36 +221,112 @@ func (dbc *QData) SQLDB() QInterface {
 	return dbc.db
 }
 
+// Err returns the error of the failed Begin, nil otherwise
+func (dbc *QData) Err() error {
+	return dbc.err
+}
+
 // Model returns a qStat object with default method SQLSelect
 func (dbc *QData) Model(model any) *QStat {
 	stat := QStat{
 		dbc:          dbc.clone(),
 		preparedStmt: false,
+		looseDecode:  dbc.config.LooseDecode,
 		Variables:    map[string]string{},
 	}
 	stat.Model(model)
+	if dbc.err != nil {
+		stat.err = dbc.err
+	}
 
 	return &stat
 }
 
+// BeginContext returns a transaction handler bound to ctx
+func (c *QData) BeginContext(ctx context.Context) *QData {
+	return c.WithContext(ctx).Begin()
+}
+
 // Begin returns a transaction handler
+// If the transaction can not be started, the error is kept by the handler,
+// and it is returned by Err(), the statements and the Fin* functions
+// Begin on a transaction handler creates a SAVEPOINT, see BeginTx
 func (c *QData) Begin() *QData {
+	newc, err := c.BeginTx(c.Context(), nil)
+	if err != nil {
+		newc = c.clone()
+		newc.err = err
+	}
+
+	return newc
+}
+
+// BeginTx starts a transaction with ctx and opts
+// If c is a transaction handler, a SAVEPOINT of the transaction is created instead,
+// Commit and Rollback of the returned handler release and roll back to the savepoint,
+// the options can not be set on a savepoint, ErrSavepointOptions is returned if opts is not nil
+func (c *QData) BeginTx(ctx context.Context, opts *sql.TxOptions) (*QData, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.tx != nil && opts != nil {
+		return nil, ErrSavepointOptions
+	}
 	newc := c.clone()
-	tx, err := newc.db.Begin()
+	newc.ctx = ctx
+	if c.tx != nil {
+		newc.savepoint = fmt.Sprintf("sp_%d", atomic.AddInt64(c.txSeq, 1))
+		if _, err := c.tx.ExecContext(ctx, fmt.Sprintf("SAVEPOINT %s", newc.savepoint)); err != nil {
+			return nil, err
+		}
+
+		return newc, nil
+	}
+
+	tx, err := newc.db.BeginTx(ctx, opts)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	newc.tx = tx
+	newc.txSeq = new(int64)
 	newc.preparedStmt = &sync.Map{}
 
-	return newc
+	return newc, nil
+}
+
+// InTx reports whether c is a transaction or savepoint handler not finished yet
+func (c *QData) InTx() bool {
+	return c.tx != nil
+}
+
+// commitTx commits the transaction or releases the savepoint
+func (c *QData) commitTx() error {
+	if c.savepoint != "" {
+		_, err := c.tx.ExecContext(c.Context(), fmt.Sprintf("RELEASE SAVEPOINT %s", c.savepoint))
+		return err
+	}
+
+	return c.tx.Commit()
+}
+
+// rollbackTx rolls back the transaction or to the savepoint
+func (c *QData) rollbackTx() error {
+	if c.savepoint != "" {
+		_, err := c.tx.ExecContext(c.Context(), fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", c.savepoint))
+		return err
+	}
+
+	return c.tx.Rollback()
 }
 
 // Commit will do as it named
 func (c *QData) Commit() error {
+	if c.err != nil {
+		return c.err
+	}
 	if c.tx != nil {
-		err := c.tx.Commit()
+		err := c.commitTx()
 		c.tx = nil
 
 		return err
@@ -197,8 +337,11 @@ func (c *QData) Commit() error {
 
 // Rollback will do as it named
 func (c *QData) Rollback() error {
+	if c.err != nil {
+		return c.err
+	}
 	if c.tx != nil {
-		err := c.tx.Rollback()
+		err := c.rollbackTx()
 		c.tx = nil
 
 		return err
@@ -207,17 +350,22 @@ func (c *QData) Rollback() error {
 	return nil
 }
 
-func (c *QData) FinAfterFuncOK(txFunc func() error) error {
-	var err error
+// FinAfterFuncOK commits the transaction (or releases the savepoint) if txFunc returns nil,
+// otherwise rolls it back
+func (c *QData) FinAfterFuncOK(txFunc func() error) (err error) {
+	if c.err != nil {
+		return c.err
+	}
 	if c.tx != nil {
 		defer func() {
 			if p := recover(); p != nil {
-				c.tx.Rollback()
+				c.rollbackTx()
+				c.tx = nil
 				panic(p)
 			} else if err != nil {
-				c.tx.Rollback()
+				c.rollbackTx()
 			} else {
-				err = c.tx.Commit()
+				err = c.commitTx()
 			}
 			c.tx = nil
 		}()
@@ -232,13 +380,16 @@ func (c *QData) FinAfterFuncOK(txFunc func() error) error {
 
 func (c *QData) FinDefaultCommit() error {
 	var err error
+	if c.err != nil {
+		return c.err
+	}
 
 	if c.tx != nil {
 		if p := recover(); p != nil {
-			c.tx.Rollback()
+			c.rollbackTx()
 			panic(p)
 		} else {
-			err = c.tx.Commit()
+			err = c.commitTx()
 		}
 		c.tx = nil
 	}
@@ -248,14 +399,17 @@ func (c *QData) FinDefaultCommit() error {
 
 func (c *QData) FinDefaultRollback() error {
 	var err error
+	if c.err != nil {
+		return c.err
+	}
 
 	if c.tx != nil {
 		if p := recover(); p != nil {
-			c.tx.Rollback()
+			c.rollbackTx()
 			c.tx = nil
 			panic(p)
 		}
-		err = c.tx.Rollback()
+		err = c.rollbackTx()
 		c.tx = nil
 	}
 
@@ -263,27 +417,54 @@ func (c *QData) FinDefaultRollback() error {
 }
 
 func (c *QData) QueryUnsafe(query string, args ...any) (*sql.Rows, error) {
+	return c.QueryContextUnsafe(c.Context(), query, args...)
+}
+
+func (c *QData) QueryContextUnsafe(ctx context.Context, query string, args ...any) (rows *sql.Rows, err error) {
+	defer c.logUnsafe(ctx, time.Now(), query, args, &err)
 	if c.tx != nil {
-		return c.tx.Query(query, args...)
+		return c.tx.QueryContext(ctx, query, args...)
 	} else {
-		return c.db.Query(query, args...)
+		return c.db.QueryContext(ctx, query, args...)
 	}
 }
 
 func (c *QData) QueryRowUnsafe(query string, args ...any) (row *sql.Row) {
+	return c.QueryRowContextUnsafe(c.Context(), query, args...)
+}
+
+func (c *QData) QueryRowContextUnsafe(ctx context.Context, query string, args ...any) (row *sql.Row) {
+	start := time.Now()
 	if c.tx != nil {
-		row = c.tx.QueryRow(query, args...)
+		row = c.tx.QueryRowContext(ctx, query, args...)
 	} else {
-		row = c.db.QueryRow(query, args...)
+		row = c.db.QueryRowContext(ctx, query, args...)
 	}
+	err := row.Err()
+	c.logUnsafe(ctx, start, query, args, &err)
 
 	return
 }
 
 func (c *QData) ExecUnsafe(query string, args ...any) (sql.Result, error) {
+	return c.ExecContextUnsafe(c.Context(), query, args...)
+}
+
+func (c *QData) ExecContextUnsafe(ctx context.Context, query string, args ...any) (res sql.Result, err error) {
+	defer c.logUnsafe(ctx, time.Now(), query, args, &err)
 	if c.tx != nil {
-		return c.tx.Exec(query, args...)
+		return c.tx.ExecContext(ctx, query, args...)
 	} else {
-		return c.db.Exec(query, args...)
+		return c.db.ExecContext(ctx, query, args...)
 	}
 }
+
+// logUnsafe logs the raw statement, err is read after the statement returns
+func (c *QData) logUnsafe(ctx context.Context, start time.Time, query string, args []any, err *error) {
+	c.logEvent(ctx, start, QLogEvent{
+		SQL:    query,
+		Args:   args,
+		Method: methodUnsafe,
+		Err:    *err,
+	})
+}
@@ -0,0 +1,206 @@
+package dataq
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeResponse is the canned response of the fake driver
+type fakeResponse struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	AffectedRows int64
+	LastInsertId int64
+	Err          error
+	// RowsErr is returned by the cursor after the rows
+	RowsErr error
+}
+
+// fakeHandler answers the statements sent to the fake driver
+type fakeHandler func(query string, args []driver.NamedValue) fakeResponse
+
+var (
+	fakeHandlers  sync.Map
+	fakeHandlerID int64
+)
+
+func init() {
+	sql.Register("dataqfake", fakeDriver{})
+}
+
+// newFakeData returns a QData backed by the fake driver, the statements are answered by handler
+func newFakeData(t *testing.T, config Config, handler fakeHandler) *QData {
+	t.Helper()
+	name := fmt.Sprintf("fake%d", atomic.AddInt64(&fakeHandlerID, 1))
+	fakeHandlers.Store(name, handler)
+	db, err := sql.Open("dataqfake", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeHandlers.Delete(name)
+	})
+
+	return &QData{
+		db:     db,
+		config: config,
+		shared: &sharedConfig{
+			store:        &sync.Map{},
+			preparedStmt: map[string]*sql.Stmt{},
+		},
+		preparedStmt: &sync.Map{},
+	}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	handler, ok := fakeHandlers.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown handler %s", name)
+	}
+
+	return &fakeConn{handler: handler.(fakeHandler)}, nil
+}
+
+type fakeConn struct {
+	handler fakeHandler
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	query := "BEGIN"
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		query += " ISOLATION LEVEL " + sql.IsolationLevel(opts.Isolation).String()
+	}
+	if opts.ReadOnly {
+		query += " READ ONLY"
+	}
+	if res := c.handler(query, nil); res.Err != nil {
+		return nil, res.Err
+	}
+
+	return fakeTx{conn: c}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	res := c.handler(query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	return fakeResult{res: res}, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	res := c.handler(query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	return &fakeRows{res: res}, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (tx fakeTx) Commit() error {
+	return tx.conn.handler("COMMIT", nil).Err
+}
+
+func (tx fakeTx) Rollback() error {
+	return tx.conn.handler("ROLLBACK", nil).Err
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return named
+}
+
+type fakeResult struct {
+	res fakeResponse
+}
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return r.res.LastInsertId, nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return r.res.AffectedRows, nil
+}
+
+type fakeRows struct {
+	res fakeResponse
+	idx int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.res.Columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.res.Rows) {
+		if r.res.RowsErr != nil {
+			return r.res.RowsErr
+		}
+		return io.EOF
+	}
+	copy(dest, r.res.Rows[r.idx])
+	r.idx++
+
+	return nil
+}
@@ -1,11 +1,11 @@
 package dataq
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // the return Value can be Kind() of Slice
@@ -87,9 +87,23 @@ func parseInterface(typeName reflect.Type, str string) (val interface{}) {
 	return val
 }
 
+var qValuerType = reflect.TypeOf((*qValuer)(nil)).Elem()
+
+// asNullNever is the AsNull of the pointer fields,
+// it equals to no value so that nil is written as NULL
+type asNullNever struct{}
+
 func getAsNull(field reflect.StructField) (asNull interface{}) {
 	asNulls := field.Tag.Get("ASNULL")
 	if asNulls == "" {
+		if field.Type.Kind() == reflect.Ptr {
+			return asNullNever{}
+		}
+		// the NULL value of driver.Valuer is nil
+		if field.Type.Implements(valuerType) || reflect.PointerTo(field.Type).Implements(valuerType) ||
+			field.Type.Implements(qValuerType) {
+			return nil
+		}
 		switch field.Type.Name() {
 		case "int", "int8", "int16", "int32", "int64":
 			fallthrough
@@ -103,8 +117,6 @@ func getAsNull(field reflect.StructField) (asNull interface{}) {
 			asNull = false
 		case "Time":
 			asNull = ConfigAsNullDateTimeFormat
-		case "QBool", "QInt", "QFloat64", "QString", "QStrings", "QTime":
-			asNull = nil
 		default:
 			if field.Type.Kind() == reflect.Map {
 				asNull = "{}"
@@ -160,205 +172,284 @@ func isEqual(val1, val2 interface{}) bool {
 	return fmt.Sprintf("%#v", val1) == fmt.Sprintf("%#v", val2)
 }
 
+// qStructMeta is the layout of a struct type parsed from its tags,
+// it is shared by all the models of the same type and must not be modified
+type qStructMeta struct {
+	Table      string
+	TableAlias string
+	CountOn    string
+	Index      []qField
+	Fields     []qField
+	Joins      []string
+	Wheres     []string
+	Schema     []string
+	Relations  []qRelation
+	QueryOnly  bool
+	// err is the first error of the tags, returned by analyseStruct
+	err error
+}
+
+// qMetaKey is the key of the cached layout, the names depend on the naming strategy
+type qMetaKey struct {
+	tableMeta reflect.Type
+	naming    *NamingStrategy
+}
+
+// structMetaCache map[qMetaKey]*qStructMeta
+var structMetaCache sync.Map
+
+// getStructMeta returns the cached layout of the struct type
+func getStructMeta(tableMeta reflect.Type, naming *NamingStrategy) *qStructMeta {
+	key := qMetaKey{tableMeta, naming}
+	if meta, ok := structMetaCache.Load(key); ok {
+		return meta.(*qStructMeta)
+	}
+
+	meta, _ := structMetaCache.LoadOrStore(key, parseStructMeta(tableMeta, naming))
+
+	return meta.(*qStructMeta)
+}
+
 //	<table> {
 //		<column>: <values> `<tag>`
 //	}
 //
 // `COL`: "TABLE.FIELD"
-func analyseStruct(data interface{}) (retStruct qStruct, err error) {
-	tableValues := structToValue(data)
-	tableMeta := tableValues.Type()
+func parseStructMeta(tableMeta reflect.Type, naming *NamingStrategy) *qStructMeta {
+	parser := qMetaParser{
+		naming: naming,
+	}
+
+	parser.parseFields(tableMeta, nil, "", naming.tableName(tableMeta.Name()), false)
+
+	// `FROM <tablename>` will omit
+	if parser.noFrom {
+		parser.meta.QueryOnly = true
+		parser.meta.Table = ""
+	}
+
+	return &parser.meta
+}
+
+type qMetaParser struct {
+	meta   qStructMeta
+	naming *NamingStrategy
+	noFrom bool
+}
+
+// parseFields parses the fields of the struct at the index path,
+// the embedded structs are flattened and the nested structs with `PREFIX` or `TABLE` are mapped with their own table,
+// it returns the table of the following fields
+func (parser *qMetaParser) parseFields(tableMeta reflect.Type, path []int, prefix, table string, nested bool) string {
 	var (
-		table      string
 		tableAlias string
 		theCol     string
 		theTable   string
-		noFrom     = false
 	)
-	if tableValues.Kind() != reflect.Slice {
-		table = tableMeta.Name()
-		for i := 0; i < tableValues.NumField(); i++ {
 
-			if hasTag(tableMeta.Field(i).Tag, "OMIT") {
-				continue
-			}
-
-			theCol, theTable, table, tableAlias = getColNameTable(tableMeta.Field(i).Name, tableMeta.Field(i).Tag, table)
-
-			_field := qField{
-				Table:   theTable,
-				ColName: theCol,
-				ValIdx:  i,
+	for i := 0; i < tableMeta.NumField(); i++ {
+		field := tableMeta.Field(i)
+		tag, err := parseTag(field.Tag)
+		if err != nil {
+			if parser.meta.err == nil {
+				parser.meta.err = fmt.Errorf("%w of %s.%s", err, tableMeta.Name(), field.Name)
 			}
+			continue
+		}
+		field.Tag = tag
+		index := append(append([]int(nil), path...), i)
 
-			if hasTag(tableMeta.Field(i).Tag, "NOFROM") {
-				noFrom = true
-			} else if i == 0 {
-				retStruct.Table = table
-				retStruct.TableAlias = tableAlias
-				retStruct.CountOn = tableMeta.Field(i).Tag.Get("COUNTON")
-			}
+		if hasTag(field.Tag, "OMIT") {
+			continue
+		}
 
-			if hasTag(tableMeta.Field(i).Tag, "RAW") {
-				retStruct.QueryOnly = true
-				_field.ColName = tableMeta.Field(i).Tag.Get("COL")
-				_field.Table = ""
-			}
+		if relation := getRelation(field, index); relation != nil {
+			parser.meta.Relations = append(parser.meta.Relations, *relation)
+			continue
+		}
 
-			_field.Schema = tableMeta.Field(i).Tag.Get("SCHEMAF")
-			if hasTag(tableMeta.Field(i).Tag, "SCHEMAT") {
-				retStruct.Schema = append(retStruct.Schema, tableMeta.Field(i).Tag.Get("SCHEMAT"))
+		if isEmbeddedStruct(field) {
+			table = parser.parseFields(field.Type, index, prefix, table, nested)
+			continue
+		}
+		if isNestedStruct(field) {
+			nextTable := table
+			if hasTag(field.Tag, "TABLE") {
+				nextTable = field.Tag.Get("TABLE")
 			}
+			parser.parseFields(field.Type, index, prefix+field.Tag.Get("PREFIX"), nextTable, true)
+			continue
+		}
 
-			if !emptyTag(tableMeta.Field(i).Tag, "JOIN") {
-				retStruct.Joins = append(retStruct.Joins, tableMeta.Field(i).Tag.Get("JOIN"))
-			}
-			if !emptyTag(tableMeta.Field(i).Tag, "WHERE") {
-				retStruct.Wheres = append(retStruct.Wheres, tableMeta.Field(i).Tag.Get("WHERE"))
-			}
+		theCol, theTable, table, tableAlias = getColNameTable(parser.naming.columnName(field.Name), field.Tag, table)
+		theCol = prefix + theCol
 
-			_field.AsNull = getAsNull(tableMeta.Field(i))
-			_field.AsClear = getAsClear(tableMeta.Field(i))
-			_field.Alt = getAlt(tableMeta.Field(i))
-			_field.Self = tableMeta.Field(i).Tag.Get("SELF")
-			if tableMeta.Field(i).Tag.Get("TABLEAS") != "" {
-				_field.Table = tableMeta.Field(i).Tag.Get("TABLEAS")
-			}
-			_field.ColAlias = tableMeta.Field(i).Tag.Get("COLAS")
-			_field.Json = getTagJson(tableMeta.Field(i))
+		_field := qField{
+			Table:   theTable,
+			ColName: theCol,
+			ValIdx:  index,
+		}
 
-			if hasTag(tableMeta.Field(i).Tag, "JSONCAST") {
-				_field.JsonCast = true
-			}
+		if hasTag(field.Tag, "NOFROM") {
+			parser.noFrom = true
+		} else if !nested && isFirstField(index) {
+			parser.meta.Table = table
+			parser.meta.TableAlias = tableAlias
+			parser.meta.CountOn = field.Tag.Get("COUNTON")
+		}
 
-			if hasTag(tableMeta.Field(i).Tag, "PASSUPDATE") {
-				_field.PassUpdate = true
-			}
+		if hasTag(field.Tag, "RAW") {
+			parser.meta.QueryOnly = true
+			_field.ColName = field.Tag.Get("COL")
+			_field.Table = ""
+		}
 
-			_field.JsonMerge = tableMeta.Field(i).Tag.Get("JSONMERGE")
-			_field.JsonMergePreserve = tableMeta.Field(i).Tag.Get("JSONMERGEPRESERVE")
-			_field.JsonMergePatch = tableMeta.Field(i).Tag.Get("JSONMERGEPATCH")
-			_field.JsonArrayAppend = tableMeta.Field(i).Tag.Get("JSONARRAYAPPEND")
+		_field.Schema = field.Tag.Get("SCHEMAF")
+		if hasTag(field.Tag, "SCHEMAT") {
+			parser.meta.Schema = append(parser.meta.Schema, field.Tag.Get("SCHEMAT"))
+		}
 
-			if hasTag(tableMeta.Field(i).Tag, "INIT") {
-				_field.Init = true
-			}
+		if !emptyTag(field.Tag, "JOIN") {
+			parser.meta.Joins = append(parser.meta.Joins, field.Tag.Get("JOIN"))
+		}
+		if !emptyTag(field.Tag, "WHERE") {
+			parser.meta.Wheres = append(parser.meta.Wheres, field.Tag.Get("WHERE"))
+		}
 
-			if hasTag(tableMeta.Field(i).Tag, "INDEX") {
-				_field.IsIndex = true
-				retStruct.Index = append(retStruct.Index, _field)
-			}
+		_field.AsNull = getAsNull(field)
+		_field.AsClear = getAsClear(field)
+		_field.Alt = getAlt(field)
+		_field.Self = field.Tag.Get("SELF")
+		if field.Tag.Get("TABLEAS") != "" {
+			_field.Table = field.Tag.Get("TABLEAS")
+		}
+		_field.ColAlias = field.Tag.Get("COLAS")
+		_field.Json = getTagJson(field)
 
-			retStruct.Fields = append(retStruct.Fields, _field)
+		if hasTag(field.Tag, "JSONCAST") {
+			_field.JsonCast = true
 		}
 
-		retStruct.Length = 1
-		retStruct.Value = tableValues
-	} else {
-		if tableValues.Len() == 0 || tableValues.Cap() == 0 {
-			// return retStruct, errors.New("dataq: Data set is empty")
-			// TODO: restrict freeLength!
-			if !tableValues.CanSet() {
-				return retStruct, errors.New("dataq: underlying variable cannot be set")
-			}
-			retStruct.freeLength = true
+		if hasTag(field.Tag, "PASSUPDATE") {
+			_field.PassUpdate = true
 		}
-		tableMeta = tableValues.Type().Elem()
-		table = tableMeta.Name()
-		_lenField := tableMeta.NumField()
 
-		for i := 0; i < _lenField; i++ {
+		_field.JsonMerge = field.Tag.Get("JSONMERGE")
+		_field.JsonMergePreserve = field.Tag.Get("JSONMERGEPRESERVE")
+		_field.JsonMergePatch = field.Tag.Get("JSONMERGEPATCH")
+		_field.JsonArrayAppend = field.Tag.Get("JSONARRAYAPPEND")
 
-			if hasTag(tableMeta.Field(i).Tag, "OMIT") {
-				continue
-			}
+		if hasTag(field.Tag, "INIT") {
+			_field.Init = true
+		}
 
-			theCol, theTable, table, tableAlias = getColNameTable(tableMeta.Field(i).Name, tableMeta.Field(i).Tag, table)
-			_field := qField{
-				Table:   theTable,
-				ColName: theCol,
-				ValIdx:  i,
-				IsIndex: false,
-			}
+		if hasTag(field.Tag, "AUTOCREATE") {
+			_field.AutoCreate = true
+		}
+		if hasTag(field.Tag, "AUTOUPDATE") {
+			_field.AutoUpdate = true
+		}
+		_field.SoftDelete = getSoftDelete(field)
+		if hasTag(field.Tag, "VERSION") {
+			_field.Version = true
+		}
 
-			if hasTag(tableMeta.Field(i).Tag, "NOFROM") {
-				noFrom = true
-			} else if i == 0 {
-				retStruct.Table = table
-				retStruct.TableAlias = tableAlias
-				retStruct.CountOn = tableMeta.Field(i).Tag.Get("COUNTON")
-			}
+		if hasTag(field.Tag, "INDEX") {
+			_field.IsIndex = true
+			parser.meta.Index = append(parser.meta.Index, _field)
+		}
 
-			if hasTag(tableMeta.Field(i).Tag, "RAW") {
-				retStruct.QueryOnly = true
-				_field.ColName = tableMeta.Field(i).Tag.Get("COL")
-				_field.Table = ""
-			}
+		parser.meta.Fields = append(parser.meta.Fields, _field)
+	}
 
-			if hasTag(tableMeta.Field(i).Tag, "SCHEMAF") {
-				_field.Schema = tableMeta.Field(i).Tag.Get("SCHEMAF")
-			}
-			if hasTag(tableMeta.Field(i).Tag, "SCHEMAT") {
-				retStruct.Schema = append(retStruct.Schema, tableMeta.Field(i).Tag.Get("SCHEMAT"))
-			}
+	return table
+}
 
-			if !emptyTag(tableMeta.Field(i).Tag, "JOIN") {
-				retStruct.Joins = append(retStruct.Joins, tableMeta.Field(i).Tag.Get("JOIN"))
-			}
-			if !emptyTag(tableMeta.Field(i).Tag, "WHERE") {
-				retStruct.Wheres = append(retStruct.Wheres, tableMeta.Field(i).Tag.Get("WHERE"))
-			}
+// isColumnStruct reports whether the struct type is stored in one column
+func isColumnStruct(fieldType reflect.Type) bool {
+	return fieldType == timeType || fieldType.Implements(valuerType) ||
+		reflect.PointerTo(fieldType).Implements(scannerType) || fieldType.Implements(qValuerType)
+}
 
-			_field.AsNull = getAsNull(tableMeta.Field(i))
-			_field.AsClear = getAsClear(tableMeta.Field(i))
-			_field.Alt = getAlt(tableMeta.Field(i))
-			_field.Self = tableMeta.Field(i).Tag.Get("SELF")
-			if tableMeta.Field(i).Tag.Get("TABLEAS") != "" {
-				_field.Table = tableMeta.Field(i).Tag.Get("TABLEAS")
-			}
-			_field.ColAlias = tableMeta.Field(i).Tag.Get("COLAS")
-			_field.Json = getTagJson(tableMeta.Field(i))
+// isEmbeddedStruct reports whether the field is an anonymous struct to be flattened
+func isEmbeddedStruct(field reflect.StructField) bool {
+	return field.Anonymous && field.Type.Kind() == reflect.Struct && !isColumnStruct(field.Type) &&
+		emptyTag(field.Tag, "COL") && emptyTag(field.Tag, "JSON")
+}
 
-			if hasTag(tableMeta.Field(i).Tag, "JSONCAST") {
-				_field.JsonCast = true
-			}
+// isNestedStruct reports whether the field is a named struct with `PREFIX` or `TABLE`,
+// e.g. the columns of the joined table
+func isNestedStruct(field reflect.StructField) bool {
+	return !field.Anonymous && field.Type.Kind() == reflect.Struct && !isColumnStruct(field.Type) &&
+		(hasTag(field.Tag, "PREFIX") || hasTag(field.Tag, "TABLE")) && emptyTag(field.Tag, "COL") && emptyTag(field.Tag, "JSON")
+}
 
-			if hasTag(tableMeta.Field(i).Tag, "PASSUPDATE") {
-				_field.PassUpdate = true
-			}
+// isFirstField reports whether the index path is the first field of the model
+func isFirstField(index []int) bool {
+	for _, _idx := range index {
+		if _idx != 0 {
+			return false
+		}
+	}
 
-			_field.JsonMerge = tableMeta.Field(i).Tag.Get("JSONMERGE")
-			_field.JsonMergePreserve = tableMeta.Field(i).Tag.Get("JSONMERGEPRESERVE")
-			_field.JsonMergePatch = tableMeta.Field(i).Tag.Get("JSONMERGEPATCH")
-			_field.JsonArrayAppend = tableMeta.Field(i).Tag.Get("JSONARRAYAPPEND")
+	return true
+}
 
-			if hasTag(tableMeta.Field(i).Tag, "INIT") {
-				_field.Init = true
-			}
+// fieldName returns the name of the field at the index path, e.g. `Dept.Name`,
+// the embedded structs are not named as their fields are promoted
+func fieldName(tableMeta reflect.Type, index []int) string {
+	var names []string
+	for _, _idx := range index {
+		field := tableMeta.Field(_idx)
+		if !field.Anonymous || len(names) == len(index)-1 {
+			names = append(names, field.Name)
+		}
+		tableMeta = field.Type
+	}
 
-			retStruct.Length = tableValues.Len()
+	return strings.Join(names, ".")
+}
 
-			if hasTag(tableMeta.Field(i).Tag, "INDEX") {
-				_field.IsIndex = true
-				retStruct.Index = append(retStruct.Index, _field)
-			}
+// analyseStruct returns the qStruct of the model,
+// the layout comes from the cache, the slices are copied as the statement can modify them
+func analyseStruct(data interface{}, naming *NamingStrategy) (retStruct qStruct, err error) {
+	tableValues := structToValue(data)
+	if tableValues.Kind() != reflect.Slice && tableValues.Kind() != reflect.Struct ||
+		tableValues.Kind() == reflect.Slice && tableValues.Type().Elem().Kind() != reflect.Struct {
+		return retStruct, ErrInvalidModel
+	}
+	tableMeta := tableValues.Type()
 
-			retStruct.Fields = append(retStruct.Fields, _field)
+	if tableValues.Kind() != reflect.Slice {
+		retStruct.Length = 1
+	} else {
+		if tableValues.Len() == 0 || tableValues.Cap() == 0 {
+			// return retStruct, errors.New("dataq: Data set is empty")
+			// TODO: restrict freeLength!
+			if !tableValues.CanSet() {
+				return retStruct, fmt.Errorf("%w: underlying variable cannot be set", ErrNotSettable)
+			}
+			retStruct.freeLength = true
 		}
-		retStruct.Value = tableValues
+		tableMeta = tableMeta.Elem()
+		retStruct.Length = tableValues.Len()
 	}
 
-	// `FROM <tablename>` will omit
-	if noFrom {
-		retStruct.QueryOnly = true
-		retStruct.Table = ""
+	meta := getStructMeta(tableMeta, naming)
+	if meta.err != nil {
+		return retStruct, meta.err
 	}
+	retStruct.Table = meta.Table
+	retStruct.TableAlias = meta.TableAlias
+	retStruct.CountOn = meta.CountOn
+	retStruct.QueryOnly = meta.QueryOnly
+	retStruct.Fields = append([]qField(nil), meta.Fields...)
+	retStruct.Index = append([]qField(nil), meta.Index...)
+	retStruct.Joins = append([]string(nil), meta.Joins...)
+	retStruct.Wheres = append([]string(nil), meta.Wheres...)
+	retStruct.Schema = append([]string(nil), meta.Schema...)
+	retStruct.Relations = meta.Relations
+	retStruct.Value = tableValues
 
 	return retStruct, nil
 }
-
-func panicErrHandle(err error) {
-	if err != nil {
-		panic(err.Error())
-	}
-}
@@ -0,0 +1,236 @@
+package dataq
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// QCond is the condition of WHERE built by Eq, In, And, Or, ..., see QStat.Where
+// The columns are quoted with the dialect, e.g. Eq("Person.ID", 1) -> `Person`.`ID` = ?
+type QCond interface {
+	build(dialect Dialect) (string, []any, error)
+}
+
+type qCompare struct {
+	col      string
+	operator string
+	val      any
+}
+
+type qIn struct {
+	col  string
+	vals []any
+}
+
+type qBetween struct {
+	col      string
+	from, to any
+}
+
+type qIsNull struct {
+	col string
+}
+
+type qLogic struct {
+	operator string
+	conds    []QCond
+}
+
+type qNot struct {
+	cond QCond
+}
+
+type qRaw struct {
+	template string
+	vals     []any
+}
+
+// Eq is `col = ?`
+func Eq(col string, val any) QCond {
+	return qCompare{col, "=", val}
+}
+
+// Neq is `col <> ?`
+func Neq(col string, val any) QCond {
+	return qCompare{col, "<>", val}
+}
+
+// Gt is `col > ?`
+func Gt(col string, val any) QCond {
+	return qCompare{col, ">", val}
+}
+
+// Gte is `col >= ?`
+func Gte(col string, val any) QCond {
+	return qCompare{col, ">=", val}
+}
+
+// Lt is `col < ?`
+func Lt(col string, val any) QCond {
+	return qCompare{col, "<", val}
+}
+
+// Lte is `col <= ?`
+func Lte(col string, val any) QCond {
+	return qCompare{col, "<=", val}
+}
+
+// Like is `col LIKE ?`
+func Like(col string, pattern string) QCond {
+	return qCompare{col, "LIKE", pattern}
+}
+
+// In is `col IN (?,?,...)`, the values can be one slice, the empty values match nothing
+func In(col string, vals ...any) QCond {
+	if len(vals) == 1 {
+		if expanded, ok := expandSlice(vals[0]); ok {
+			vals = expanded
+		}
+	}
+
+	return qIn{col, vals}
+}
+
+// Between is `col BETWEEN ? AND ?`
+func Between(col string, from, to any) QCond {
+	return qBetween{col, from, to}
+}
+
+// IsNull is `col IS NULL`, Not(IsNull(col)) is `col IS NOT NULL`
+func IsNull(col string) QCond {
+	return qIsNull{col}
+}
+
+// And joins the conditions with AND in parentheses
+func And(conds ...QCond) QCond {
+	return qLogic{"AND", conds}
+}
+
+// Or joins the conditions with OR in parentheses
+func Or(conds ...QCond) QCond {
+	return qLogic{"OR", conds}
+}
+
+// Not negates the condition
+func Not(cond QCond) QCond {
+	return qNot{cond}
+}
+
+// Raw is the SQL fragment with `?` placeholders, the `?` of a slice value is expanded to `?,?,...`
+func Raw(template string, vals ...any) QCond {
+	return qRaw{template, vals}
+}
+
+func (c qCompare) build(dialect Dialect) (string, []any, error) {
+	return fmt.Sprintf("%s %s ?", quoteColumn(dialect, c.col), c.operator), []any{c.val}, nil
+}
+
+func (c qIn) build(dialect Dialect) (string, []any, error) {
+	if len(c.vals) == 0 {
+		return "1=0", nil, nil
+	}
+
+	return fmt.Sprintf("%s IN (%s)", quoteColumn(dialect, c.col), placeholders(len(c.vals))), c.vals, nil
+}
+
+func (c qBetween) build(dialect Dialect) (string, []any, error) {
+	return fmt.Sprintf("%s BETWEEN ? AND ?", quoteColumn(dialect, c.col)), []any{c.from, c.to}, nil
+}
+
+func (c qIsNull) build(dialect Dialect) (string, []any, error) {
+	return fmt.Sprintf("%s IS NULL", quoteColumn(dialect, c.col)), nil, nil
+}
+
+func (c qLogic) build(dialect Dialect) (string, []any, error) {
+	var (
+		parts []string
+		vals  []any
+	)
+	for _, _cond := range c.conds {
+		if _cond == nil {
+			continue
+		}
+		part, partVals, err := _cond.build(dialect)
+		if err != nil {
+			return "", nil, err
+		}
+		if part == "" {
+			continue
+		}
+		parts = append(parts, part)
+		vals = append(vals, partVals...)
+	}
+	switch len(parts) {
+	case 0:
+		return "", nil, nil
+	case 1:
+		return parts[0], vals, nil
+	}
+
+	return "(" + strings.Join(parts, " "+c.operator+" ") + ")", vals, nil
+}
+
+func (c qNot) build(dialect Dialect) (string, []any, error) {
+	if c.cond == nil {
+		return "", nil, nil
+	}
+	part, vals, err := c.cond.build(dialect)
+	if err != nil || part == "" {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("NOT (%s)", part), vals, nil
+}
+
+func (c qRaw) build(dialect Dialect) (string, []any, error) {
+	var (
+		sql  strings.Builder
+		vals []any
+		n    = 0
+	)
+	for _, _char := range c.template {
+		if _char != '?' || n >= len(c.vals) {
+			sql.WriteRune(_char)
+			continue
+		}
+		if expanded, ok := expandSlice(c.vals[n]); ok {
+			sql.WriteString(placeholders(len(expanded)))
+			vals = append(vals, expanded...)
+		} else {
+			sql.WriteRune(_char)
+			vals = append(vals, c.vals[n])
+		}
+		n++
+	}
+
+	return sql.String(), append(vals, c.vals[n:]...), nil
+}
+
+// quoteColumn quotes each part of `table.column`
+func quoteColumn(dialect Dialect, col string) string {
+	parts := strings.Split(col, ".")
+	for _idx, _part := range parts {
+		parts[_idx] = dialect.Quote(_part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// expandSlice returns the elements of the slice or array value, []byte is a single value
+func expandSlice(val any) ([]any, bool) {
+	value := reflect.ValueOf(val)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array || value.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	ret := make([]any, value.Len())
+	for _idx := range ret {
+		ret[_idx] = value.Index(_idx).Interface()
+	}
+
+	return ret, true
+}
@@ -0,0 +1,63 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestCondBuild(t *testing.T) {
+	tests := []struct {
+		cond QCond
+		sql  string
+		vals []any
+	}{
+		{Eq("Person.ID", 1), "`Person`.`ID` = ?", []any{1}},
+		{Neq("NAME", "Mike"), "`NAME` <> ?", []any{"Mike"}},
+		{In("ID", []int64{1, 2}), "`ID` IN (?,?)", []any{int64(1), int64(2)}},
+		{In("ID"), "1=0", nil},
+		{Between("AGE", 18, 30), "`AGE` BETWEEN ? AND ?", []any{18, 30}},
+		{Like("NAME", "M%"), "`NAME` LIKE ?", []any{"M%"}},
+		{Not(IsNull("DELETED_AT")), "NOT (`DELETED_AT` IS NULL)", nil},
+		{And(Or(Eq("A", 1), Eq("B", 2)), Gte("C", 3)), "((`A` = ? OR `B` = ?) AND `C` >= ?)", []any{1, 2, 3}},
+		{Or(And(), Eq("A", 1)), "`A` = ?", []any{1}},
+		{Raw("A IN (?) AND B IN (?) AND C = ?", []int{1, 2}, []string{"x"}, []byte("y")), "A IN (?,?) AND B IN (?) AND C = ?", []any{1, 2, "x", []byte("y")}},
+	}
+	for _, tt := range tests {
+		sql, vals, err := tt.cond.build(MySQL)
+		if err != nil || sql != tt.sql || !reflect.DeepEqual(vals, tt.vals) {
+			t.Errorf("got %s %v, %v, want %s %v", sql, vals, err, tt.sql, tt.vals)
+		}
+	}
+
+	if sql, _, _ := Eq("Person.ID", 1).build(PostgreSQL); sql != `"Person"."ID" = ?` {
+		t.Errorf("got %s", sql)
+	}
+}
+
+func TestWhereCond(t *testing.T) {
+	var (
+		query string
+		args  []driver.NamedValue
+	)
+	db := newFakeData(t, Config{}, func(q string, a []driver.NamedValue) fakeResponse {
+		query, args = q, a
+		return fakeResponse{Columns: []string{"ID", "NAME", "AGE"}}
+	})
+
+	var persons []batchPerson
+	// the legacy Where spreads the values of the `,,,,` expansion
+	ages := []any{1, 2}
+	res := db.Model(&persons).
+		WhereCond(Or(Eq("NAME", "Mike"), Gt("AGE", 30)), In("ID", 1, 2)).
+		Where("OR", "AGE IN (,,,,)", ages...).
+		Query()
+	want := " SELECT `Person`.`ID`, `Person`.`NAME`, `Person`.`AGE` FROM `Person` WHERE (((`NAME` = ? OR `AGE` > ?) AND `ID` IN (?,?)) OR AGE IN (?,?))"
+	if res.Error != nil || query != want || len(args) != 6 {
+		t.Fatalf("got %s %v, %v, want %s", query, args, res.Error, want)
+	}
+
+	if stat := db.Model(&persons).WhereCond(And(), Or()); len(stat.Filters) != 0 {
+		t.Fatalf("got %v, want no filters", stat.Filters)
+	}
+}
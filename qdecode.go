@@ -0,0 +1,165 @@
+package dataq
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// DecodeError is returned when a column can not be decoded into the field of the model
+type DecodeError struct {
+	Column string
+	Field  string
+	Raw    string
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("dataq: can not decode column %s into field %s from %q: %v", e.Column, e.Field, e.Raw, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// parseDateTime parses the DATETIME, DATE and the RFC3339 (parseTime=true) format
+func parseDateTime(str string) (t time.Time, err error) {
+	if str == "" || strings.HasPrefix(str, "0000-00-00") {
+		return t, nil
+	}
+	for _, layout := range []string{ConfigParseDateTimeFormat, "2006-01-02 15:04:05", "2006-01-02", time.RFC3339Nano} {
+		if t, err = time.Parse(layout, str); err == nil {
+			return t, nil
+		}
+	}
+
+	return t, err
+}
+
+// setFieldValue decodes the raw column into the field of the row,
+// the field is set to its zero value when the error is returned
+// []byte is kept as the raw column unless asJSON is set
+func setFieldValue(fieldValue reflect.Value, raw []byte, asJSON bool) (err error) {
+	// NULL <-> nil
+	if fieldValue.Kind() == reflect.Ptr {
+		if raw == nil {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			return nil
+		}
+		_elem := reflect.New(fieldValue.Type().Elem())
+		err = setFieldValue(_elem.Elem(), raw, asJSON)
+		fieldValue.Set(_elem)
+
+		return err
+	}
+
+	if fieldValue.CanAddr() && fieldValue.Addr().Type().Implements(scannerType) {
+		var src any
+		if raw != nil {
+			// the raw bytes are reused by the next row
+			src = append([]byte{}, raw...)
+		}
+
+		return fieldValue.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+
+	// NULL is decoded as the zero value
+	if raw == nil && fieldValue.Kind() != reflect.Slice {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Bool:
+		boolVal, err := strconv.ParseBool(string(raw))
+		fieldValue.SetBool(boolVal)
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i64, err := strconv.ParseInt(string(raw), 10, fieldValue.Type().Bits())
+		if err != nil {
+			i64 = 0
+		}
+		fieldValue.SetInt(i64)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u64, err := strconv.ParseUint(string(raw), 10, fieldValue.Type().Bits())
+		if err != nil {
+			u64 = 0
+		}
+		fieldValue.SetUint(u64)
+		return err
+	case reflect.Float32, reflect.Float64:
+		f64, err := strconv.ParseFloat(string(raw), fieldValue.Type().Bits())
+		if err != nil {
+			f64 = 0.0
+		}
+		fieldValue.SetFloat(f64)
+		return err
+	case reflect.String:
+		fieldValue.SetString(string(raw))
+	case reflect.Struct:
+		// TODO: not only parse Time
+		_type := fieldValue.Type()
+		switch _type.PkgPath() {
+		case "time":
+			// Need timezone and can be parsed by Javascript
+			t, err := parseDateTime(string(raw))
+			fieldValue.Set(reflect.ValueOf(t))
+			return err
+		default:
+			var _ValueStruct = reflect.New(fieldValue.Type())
+			if len(raw) > 0 {
+				err = json.Unmarshal(raw, _ValueStruct.Interface())
+			}
+			fieldValue.Set(_ValueStruct.Elem())
+			return err
+		}
+	case reflect.Map:
+		var _map = reflect.New(fieldValue.Type())
+		if len(raw) > 0 {
+			err = json.Unmarshal(raw, _map.Interface())
+		}
+		fieldValue.Set(_map.Elem())
+		return err
+	case reflect.Slice:
+		if fieldValue.Type().Elem().Kind() == reflect.Uint8 && !asJSON {
+			// the raw bytes are reused by the next row
+			fieldValue.SetBytes(append([]byte{}, raw...))
+			return nil
+		}
+		if len(raw) > 0 {
+			var _ValueSlice = reflect.New(fieldValue.Type())
+			err = json.Unmarshal(raw, _ValueSlice.Interface())
+			fieldValue.Set(_ValueSlice.Elem())
+			return err
+		}
+		fieldValue.Set(reflect.MakeSlice(fieldValue.Type(), 0, 0))
+	}
+
+	return nil
+}
+
+// decodeRow decodes the scanned columns into a new row of the model
+func (stat *QStat) decodeRow(values []sql.RawBytes) (rowValue reflect.Value, err error) {
+	rowValue = reflect.New(stat.sqlStruct.getElemType()).Elem()
+
+	for i, _field := range stat.sqlStruct.Fields {
+		err = setFieldValue(rowValue.FieldByIndex(_field.ValIdx), values[i], _field.IsJSON())
+		if err != nil && !stat.looseDecode {
+			return rowValue, &DecodeError{
+				Column: _field.SelectString(stat.sqlStruct.getDialect()),
+				Field:  fieldName(rowValue.Type(), _field.ValIdx),
+				Raw:    string(values[i]),
+				Err:    err,
+			}
+		}
+	}
+
+	return rowValue, nil
+}
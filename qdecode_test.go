@@ -0,0 +1,165 @@
+package dataq
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// upperName is stored in upper case and read back in lower case
+type upperName string
+
+func (n upperName) Value() (driver.Value, error) {
+	return strings.ToUpper(string(n)), nil
+}
+
+func (n *upperName) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		*n = upperName(strings.ToLower(string(v)))
+	case nil:
+		*n = ""
+	default:
+		return fmt.Errorf("upperName: unsupported %T", src)
+	}
+
+	return nil
+}
+
+type scanPerson struct {
+	ID    int64         `COL:"ID" TABLE:"Person"`
+	Name  upperName     `COL:"NAME"`
+	Age   sql.NullInt64 `COL:"AGE"`
+	Email sql.NullString
+}
+
+func TestScannerValuer(t *testing.T) {
+	row := reflect.New(reflect.TypeOf(scanPerson{})).Elem()
+	setFieldValue(row.Field(1), []byte("MIKE"), false)
+	setFieldValue(row.Field(2), []byte("18"), false)
+	setFieldValue(row.Field(3), nil, false)
+
+	per := row.Interface().(scanPerson)
+	if per.Name != "mike" || per.Age != (sql.NullInt64{Int64: 18, Valid: true}) || per.Email.Valid {
+		t.Fatalf("got %#v", per)
+	}
+
+	_s, err := analyseStruct(&per, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, err := _s.composeInsertSQL()
+	if err != nil || !strings.Contains(sql, "`NAME`") || !strings.Contains(sql, "`AGE`") || strings.Contains(sql, "`Email`") {
+		t.Fatalf("got %s", sql)
+	}
+	for _, _val := range _s.Values {
+		if _val != int64(18) && _val != "MIKE" {
+			t.Fatalf("got %#v", _s.Values)
+		}
+	}
+}
+
+type strictPerson struct {
+	ID      int64             `COL:"ID" TABLE:"Person"`
+	Age     int               `COL:"AGE"`
+	Created time.Time         `COL:"CREATED"`
+	Extra   map[string]string `COL:"EXTRA"`
+}
+
+func TestStrictDecode(t *testing.T) {
+	stat := &QStat{dbc: &QData{}}
+	stat.SetModel(&strictPerson{})
+
+	row, err := stat.decodeRow([]sql.RawBytes{[]byte("1"), nil, []byte("2023-01-02 03:04:05"), []byte(`{"a":"b"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	per := row.Interface().(strictPerson)
+	if per.Age != 0 || per.Created != time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC) || per.Extra["a"] != "b" {
+		t.Fatalf("got %#v", per)
+	}
+
+	_, err = stat.decodeRow([]sql.RawBytes{[]byte("1"), []byte("x"), nil, nil})
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Field != "Age" || decodeErr.Column != "`Person`.`AGE`" || decodeErr.Raw != "x" {
+		t.Fatalf("got %v", err)
+	}
+
+	_, err = stat.StrictDecode(false).decodeRow([]sql.RawBytes{[]byte("1"), []byte("x"), []byte("x"), []byte("{")})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type blobPerson struct {
+	ID     int64  `COL:"ID" TABLE:"Person"`
+	Avatar []byte `COL:"AVATAR"`
+	Tags   []byte `COL:"TAGS" JSON:"tags"`
+}
+
+func TestBlobDecode(t *testing.T) {
+	stat := &QStat{dbc: &QData{}}
+	stat.SetModel(&blobPerson{})
+
+	raw := sql.RawBytes{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	row, err := stat.decodeRow([]sql.RawBytes{[]byte("1"), raw, []byte(`"AQI="`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[0] = 0
+	per := row.Interface().(blobPerson)
+	if string(per.Avatar) != "\x89PNG\x00\xff" || string(per.Tags) != "\x01\x02" {
+		t.Fatalf("got %#v", per)
+	}
+}
+
+type nullPerson struct {
+	ID      int64      `COL:"ID" TABLE:"Person" INDEX:""`
+	Name    *string    `COL:"NAME"`
+	Age     *int       `COL:"AGE"`
+	Created *time.Time `COL:"CREATED"`
+}
+
+func TestPointerNull(t *testing.T) {
+	stat := &QStat{dbc: &QData{}}
+	stat.SetModel(&nullPerson{})
+
+	row, err := stat.decodeRow([]sql.RawBytes{[]byte("1"), nil, []byte("18"), []byte("2023-01-02 03:04:05")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	per := row.Interface().(nullPerson)
+	if per.Name != nil || per.Age == nil || *per.Age != 18 || per.Created == nil || per.Created.Year() != 2023 {
+		t.Fatalf("got %#v", per)
+	}
+
+	per.Age = nil
+	_s, _ := analyseStruct(&per, nil)
+	sql, err := _s.composeUpdateSQL(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, col := range []string{"`NAME`=?", "`AGE`=?", "`CREATED`=?"} {
+		if !strings.Contains(sql, col) {
+			t.Fatalf("%s not in %s", col, sql)
+		}
+	}
+	nils := 0
+	for _, _val := range _s.Values {
+		if _val == nil {
+			nils++
+		} else if _val == "2023-01-02 03:04:05.000" || _val == int64(1) {
+			continue
+		} else {
+			t.Fatalf("got %#v", _s.Values)
+		}
+	}
+	if nils != 2 {
+		t.Fatalf("got %#v", _s.Values)
+	}
+}
@@ -0,0 +1,333 @@
+package dataq
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dialect owns the SQL syntax which differs between the databases
+// The statements are composed with `?` placeholders, they are rebound with Placeholder() at last
+type Dialect interface {
+	// Name returns the name of the dialect
+	Name() string
+	// DriverName returns the default driver name for sql.Open()
+	DriverName() string
+	// Quote quotes the identifier
+	Quote(ident string) string
+	// Placeholder returns the n-th (start from 1) placeholder
+	Placeholder(n int) string
+	// JSONExtract returns the expression of the key of the JSON column as text
+	JSONExtract(col, key string) string
+	// JSONCast casts the expression to JSON
+	JSONCast(expr string) string
+	// JSONObject returns the expression building the JSON object of the keys
+	JSONObject(keys, exprs []string) string
+	// JSONSet returns the expression setting the keys of the JSON column,
+	// and how many times the values of the exprs are bound
+	JSONSet(col string, keys, exprs []string) (string, int)
+	// JSONMergePatch returns the expression merging the patch into the target as RFC 7396
+	JSONMergePatch(target, patch string) string
+	// JSONMergePreserve returns the expression merging the patch into the target and keeping the duplicate keys,
+	// empty if not supported
+	JSONMergePreserve(target, patch string) string
+	// JSONArrayAppend returns the expression appending the value to the array at the target (`col, 'path'`),
+	// empty if not supported
+	JSONArrayAppend(target, value string) string
+	// Upsert returns the clause of INSERT updating the sets when the keys conflict
+	Upsert(keys, sets []string) (string, error)
+	// Inserted returns the expression of the value of the column to be inserted, used by the sets of Upsert
+	Inserted(col string) string
+	// UpdateLimit returns the LIMIT clause of UPDATE, empty if not supported
+	UpdateLimit(limit int) string
+	// CreateTable returns the DDL of the table
+	CreateTable(table string, defs []string) string
+	// LockFor returns the locking clause of SELECT, empty if not supported
+	LockFor(lock string) string
+	// CurrentDatabase returns the query of the name of the current database
+	CurrentDatabase() string
+}
+
+var (
+	// MySQL is the default dialect
+	MySQL Dialect = mysqlDialect{}
+	// PostgreSQL uses "ident", $n placeholders and jsonb
+	PostgreSQL Dialect = postgresDialect{}
+	// SQLite uses "ident", ? placeholders and the JSON1 functions
+	SQLite Dialect = sqliteDialect{}
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (mysqlDialect) DriverName() string {
+	return "mysql"
+}
+
+func (mysqlDialect) Quote(ident string) string {
+	return "`" + strings.ReplaceAll(ident, "`", "``") + "`"
+}
+
+func (mysqlDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (d mysqlDialect) JSONExtract(col, key string) string {
+	return fmt.Sprintf("%s->>'$.%s'", col, key)
+}
+
+func (mysqlDialect) JSONCast(expr string) string {
+	return fmt.Sprintf("CAST(%s AS JSON)", expr)
+}
+
+func (mysqlDialect) JSONObject(keys, exprs []string) string {
+	return fmt.Sprintf("JSON_OBJECT(%s)", joinPairs(keys, exprs, "'%s', %s"))
+}
+
+func (d mysqlDialect) JSONSet(col string, keys, exprs []string) (string, int) {
+	return fmt.Sprintf("IF(JSON_VALID(%s), JSON_SET(%s, %s), %s)", col, col, joinPairs(keys, exprs, "'$.%s', %s"), d.JSONObject(keys, exprs)), 2
+}
+
+func (mysqlDialect) JSONMergePatch(target, patch string) string {
+	return fmt.Sprintf("JSON_MERGE_PATCH(%s, %s)", target, patch)
+}
+
+func (mysqlDialect) JSONMergePreserve(target, patch string) string {
+	return fmt.Sprintf("JSON_MERGE_PRESERVE(%s, %s)", target, patch)
+}
+
+func (mysqlDialect) JSONArrayAppend(target, value string) string {
+	return fmt.Sprintf("JSON_ARRAY_APPEND(%s, %s)", target, value)
+}
+
+func (d mysqlDialect) Upsert(keys, sets []string) (string, error) {
+	return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s", strings.Join(sets, ", ")), nil
+}
+
+func (d mysqlDialect) Inserted(col string) string {
+	return fmt.Sprintf("VALUES(%s)", d.Quote(col))
+}
+
+func (mysqlDialect) UpdateLimit(limit int) string {
+	return fmt.Sprintf(" LIMIT %d", limit)
+}
+
+func (d mysqlDialect) CreateTable(table string, defs []string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;", d.Quote(table), strings.Join(defs, ", "))
+}
+
+func (mysqlDialect) LockFor(lock string) string {
+	return fmt.Sprintf(" FOR %s", lock)
+}
+
+func (mysqlDialect) CurrentDatabase() string {
+	return "SELECT DATABASE()"
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) DriverName() string {
+	return "postgres"
+}
+
+func (postgresDialect) Quote(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgresDialect) JSONExtract(col, key string) string {
+	return fmt.Sprintf("%s#>>'{%s}'", col, strings.ReplaceAll(key, ".", ","))
+}
+
+func (postgresDialect) JSONCast(expr string) string {
+	return fmt.Sprintf("CAST(%s AS jsonb)", expr)
+}
+
+func (postgresDialect) JSONObject(keys, exprs []string) string {
+	return fmt.Sprintf("jsonb_build_object(%s)", joinPairs(keys, exprs, "'%s', %s"))
+}
+
+func (d postgresDialect) JSONSet(col string, keys, exprs []string) (string, int) {
+	return fmt.Sprintf("COALESCE(%s, '{}'::jsonb) || %s", col, d.JSONObject(keys, exprs)), 1
+}
+
+func (postgresDialect) JSONMergePatch(target, patch string) string {
+	// only the top level keys are merged
+	return fmt.Sprintf("(COALESCE(%s, '{}'::jsonb) || %s)", target, patch)
+}
+
+func (postgresDialect) JSONMergePreserve(target, patch string) string {
+	return ""
+}
+
+func (postgresDialect) JSONArrayAppend(target, value string) string {
+	return ""
+}
+
+func (d postgresDialect) Upsert(keys, sets []string) (string, error) {
+	return upsertOnConflict(d, keys, sets)
+}
+
+func (d postgresDialect) Inserted(col string) string {
+	return "EXCLUDED." + d.Quote(col)
+}
+
+func (postgresDialect) UpdateLimit(limit int) string {
+	return ""
+}
+
+func (d postgresDialect) CreateTable(table string, defs []string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s);", d.Quote(table), strings.Join(defs, ", "))
+}
+
+func (postgresDialect) LockFor(lock string) string {
+	return fmt.Sprintf(" FOR %s", lock)
+}
+
+func (postgresDialect) CurrentDatabase() string {
+	return "SELECT current_database()"
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (sqliteDialect) DriverName() string {
+	return "sqlite3"
+}
+
+func (sqliteDialect) Quote(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (sqliteDialect) JSONExtract(col, key string) string {
+	return fmt.Sprintf("json_extract(%s, '$.%s')", col, key)
+}
+
+func (sqliteDialect) JSONCast(expr string) string {
+	return fmt.Sprintf("json(%s)", expr)
+}
+
+func (sqliteDialect) JSONObject(keys, exprs []string) string {
+	return fmt.Sprintf("json_object(%s)", joinPairs(keys, exprs, "'%s', %s"))
+}
+
+func (sqliteDialect) JSONSet(col string, keys, exprs []string) (string, int) {
+	return fmt.Sprintf("json_set(COALESCE(%s, '{}'), %s)", col, joinPairs(keys, exprs, "'$.%s', %s")), 1
+}
+
+func (sqliteDialect) JSONMergePatch(target, patch string) string {
+	return fmt.Sprintf("json_patch(%s, %s)", target, patch)
+}
+
+func (sqliteDialect) JSONMergePreserve(target, patch string) string {
+	return ""
+}
+
+func (sqliteDialect) JSONArrayAppend(target, value string) string {
+	return ""
+}
+
+func (d sqliteDialect) Upsert(keys, sets []string) (string, error) {
+	return upsertOnConflict(d, keys, sets)
+}
+
+func (d sqliteDialect) Inserted(col string) string {
+	return "EXCLUDED." + d.Quote(col)
+}
+
+func (sqliteDialect) UpdateLimit(limit int) string {
+	// only with SQLITE_ENABLE_UPDATE_DELETE_LIMIT
+	return ""
+}
+
+func (d sqliteDialect) CreateTable(table string, defs []string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s);", d.Quote(table), strings.Join(defs, ", "))
+}
+
+func (sqliteDialect) LockFor(lock string) string {
+	// SQLite locks the whole database
+	return ""
+}
+
+func (sqliteDialect) CurrentDatabase() string {
+	return "SELECT 'main'"
+}
+
+// reValues matches `VALUES(col)` of MySQL in the sets of Upsert
+var reValues = regexp.MustCompile(`(?i)\bVALUES\(([^()]*)\)`)
+
+// upsertOnConflict is the upsert of PostgreSQL and SQLite, the conflict target is required,
+// `VALUES(col)` of MySQL in the sets is replaced with `EXCLUDED.col`
+func upsertOnConflict(d Dialect, keys, sets []string) (string, error) {
+	if len(keys) == 0 {
+		return "", fmt.Errorf("%w: ON CONFLICT of %s requires the INDEX fields", ErrNoPrimaryKey, d.Name())
+	}
+	quoted := make([]string, len(keys))
+	for _idx, _key := range keys {
+		quoted[_idx] = d.Quote(_key)
+	}
+	excluded := make([]string, len(sets))
+	for _idx, _set := range sets {
+		excluded[_idx] = reValues.ReplaceAllString(_set, "EXCLUDED.$1")
+	}
+
+	return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(quoted, ", "), strings.Join(excluded, ", ")), nil
+}
+
+// joinPairs formats each pair of key and expr with format, and joins them with ", "
+func joinPairs(keys, exprs []string, format string) string {
+	pairs := make([]string, len(keys))
+	for _idx, _key := range keys {
+		pairs[_idx] = fmt.Sprintf(format, _key, exprs[_idx])
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+// rebind replaces the `?` placeholders out of the quoted strings and identifiers with the ones of the dialect
+func rebind(d Dialect, query string) string {
+	if d.Placeholder(1) == "?" {
+		return query
+	}
+
+	var (
+		sql   strings.Builder
+		quote rune
+		n     = 0
+	)
+	for _, _char := range query {
+		switch {
+		case quote != 0:
+			if _char == quote {
+				quote = 0
+			}
+		case _char == '\'' || _char == '"' || _char == '`':
+			quote = _char
+		case _char == '?':
+			n++
+			sql.WriteString(d.Placeholder(n))
+			continue
+		}
+		sql.WriteRune(_char)
+	}
+
+	return sql.String()
+}
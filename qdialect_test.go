@@ -0,0 +1,127 @@
+package dataq
+
+import (
+	"errors"
+	"testing"
+)
+
+type dialectPerson struct {
+	ID   int64  `COL:"ID" TABLE:"Person" INDEX:"" SCHEMAF:"INT NOT NULL"`
+	Name string `COL:"NAME" SCHEMAF:"VARCHAR(64)"`
+	City string `JSON:"INFO.city" SCHEMAF:"JSON" SCHEMAT:"PRIMARY KEY (ID)"`
+}
+
+func TestDialectSQL(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		insert  string
+		update  string
+		selects string
+		create  string
+	}{
+		{
+			dialect: MySQL,
+			insert:  "INSERT INTO `Person` (`ID`, `NAME`, `INFO`) VALUES (?,?,JSON_OBJECT('city', ?)) ON DUPLICATE KEY UPDATE `NAME`=VALUES(`NAME`)",
+			update:  "UPDATE `Person` SET `NAME`=?, `INFO`=IF(JSON_VALID(`INFO`), JSON_SET(`INFO`, '$.city', ?), JSON_OBJECT('city', ?)) WHERE (`ID`=?)",
+			selects: " SELECT `Person`.`ID`, `Person`.`NAME`, `Person`.`INFO`->>'$.city' FROM `Person` WHERE (`Person`.`ID` IN (?)) LIMIT 1 FOR UPDATE",
+			create:  "CREATE TABLE IF NOT EXISTS `Person` (`ID` INT NOT NULL, `NAME` VARCHAR(64), `INFO` JSON, PRIMARY KEY (ID)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;",
+		},
+		{
+			dialect: PostgreSQL,
+			insert:  `INSERT INTO "Person" ("ID", "NAME", "INFO") VALUES ($1,$2,jsonb_build_object('city', $3)) ON CONFLICT ("ID") DO UPDATE SET "NAME"=EXCLUDED."NAME"`,
+			update:  `UPDATE "Person" SET "NAME"=$1, "INFO"=COALESCE("INFO", '{}'::jsonb) || jsonb_build_object('city', $2) WHERE ("ID"=$3)`,
+			selects: ` SELECT "Person"."ID", "Person"."NAME", "Person"."INFO"#>>'{city}' FROM "Person" WHERE ("Person"."ID" IN ($1)) LIMIT 1 FOR UPDATE`,
+			create:  `CREATE TABLE IF NOT EXISTS "Person" ("ID" INT NOT NULL, "NAME" VARCHAR(64), "INFO" JSON, PRIMARY KEY (ID));`,
+		},
+		{
+			dialect: SQLite,
+			insert:  `INSERT INTO "Person" ("ID", "NAME", "INFO") VALUES (?,?,json_object('city', ?)) ON CONFLICT ("ID") DO UPDATE SET "NAME"=EXCLUDED."NAME"`,
+			update:  `UPDATE "Person" SET "NAME"=?, "INFO"=json_set(COALESCE("INFO", '{}'), '$.city', ?) WHERE ("ID"=?)`,
+			selects: ` SELECT "Person"."ID", "Person"."NAME", json_extract("Person"."INFO", '$.city') FROM "Person" WHERE ("Person"."ID" IN (?)) LIMIT 1`,
+			create:  `CREATE TABLE IF NOT EXISTS "Person" ("ID" INT NOT NULL, "NAME" VARCHAR(64), "INFO" JSON, PRIMARY KEY (ID));`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			stat := &QStat{dbc: &QData{config: Config{Dialect: tt.dialect}}}
+			person := dialectPerson{ID: 1, Name: "Mike", City: "Berlin"}
+
+			stat.SetModel(&person)
+			stat.Method = sqlInsert
+			stat.sqlStruct.OnDuplicateKeyUpdate = true
+			stat.sqlStruct.DuplicateKeyUpdateCol = map[string]any{tt.dialect.Quote("NAME"): tt.dialect.Inserted("NAME")}
+			if sql, err := stat.buildSQL(); err != nil || sql != tt.insert {
+				t.Errorf("insert: got %s, %v, want %s", sql, err, tt.insert)
+			}
+
+			stat.SetModel(&person)
+			stat.Method = sqlUpdate
+			if sql, err := stat.buildSQL(); err != nil || sql != tt.update {
+				t.Errorf("update: got %s, %v, want %s", sql, err, tt.update)
+			}
+
+			stat.SetModel(&person)
+			stat.Method = sqlSelect
+			stat.LockFor = LockForUpdate
+			if sql, err := stat.buildSQL(); err != nil || sql != tt.selects {
+				t.Errorf("select: got %s, %v, want %s", sql, err, tt.selects)
+			}
+
+			stat.SetModel(&person)
+			stat.Method = sqlCreateTable
+			if sql, err := stat.buildSQL(); err != nil || sql != tt.create {
+				t.Errorf("create: got %s, %v, want %s", sql, err, tt.create)
+			}
+		})
+	}
+}
+
+type dialectLog struct {
+	ID  int64  `COL:"ID" TABLE:"Log"`
+	Log string `COL:"LOG" JSONARRAYAPPEND:"LOG, '$'"`
+}
+
+func TestDialectNotSupported(t *testing.T) {
+	stat := &QStat{dbc: &QData{config: Config{Dialect: PostgreSQL}}}
+
+	// no conflict target
+	stat.SetModel(&dialectLog{ID: 1, Log: "a"})
+	stat.Method = sqlInsert
+	stat.sqlStruct.OnDuplicateKeyUpdate = true
+	stat.sqlStruct.DuplicateKeyUpdateCol = map[string]any{`"LOG"`: `VALUES("LOG")`}
+	if _, err := stat.buildSQL(); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Errorf("upsert: got %v, want ErrNoPrimaryKey", err)
+	}
+
+	stat.SetModel(&dialectLog{ID: 1, Log: "a"})
+	stat.Method = sqlUpdate
+	if _, err := stat.buildSQL(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("json array append: got %v, want ErrNotSupported", err)
+	}
+
+	stat.SetModel(&dialectPerson{ID: 1, Name: "Mike"})
+	stat.Method = sqlUpdate
+	stat.RowLimit = 1
+	if _, err := stat.buildSQL(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("update limit: got %v, want ErrNotSupported", err)
+	}
+
+	// VALUES(col) of MySQL is replaced with EXCLUDED.col
+	upsert, err := SQLite.Upsert([]string{"ID"}, []string{`"LOG"=values("LOG")`})
+	if want := ` ON CONFLICT ("ID") DO UPDATE SET "LOG"=EXCLUDED."LOG"`; err != nil || upsert != want {
+		t.Errorf("got %s, %v, want %s", upsert, err, want)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	sql := rebind(PostgreSQL, `SELECT '?', "a?" FROM t WHERE a = ? AND b IN (?, ?)`)
+	want := `SELECT '?', "a?" FROM t WHERE a = $1 AND b IN ($2, $3)`
+	if sql != want {
+		t.Fatalf("got %s, want %s", sql, want)
+	}
+
+	if sql := rebind(MySQL, "a = ?"); sql != "a = ?" {
+		t.Fatalf("got %s, want a = ?", sql)
+	}
+}
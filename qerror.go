@@ -0,0 +1,176 @@
+package dataq
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrNoTable is returned when the statement requires a table but the model has none
+	ErrNoTable = errors.New("dataq: table name is required")
+	// ErrNoPrimaryKey is returned when the statement requires an `INDEX` field
+	ErrNoPrimaryKey = errors.New("dataq: primary key is required")
+	// ErrNotSettable is returned when the result can not be written back into the model
+	ErrNotSettable = errors.New("dataq: model is not settable")
+	// ErrInvalidModel is returned when the model is neither a struct nor a slice of struct
+	ErrInvalidModel = errors.New("dataq: model must be a struct or a slice of struct")
+	// ErrQueryOnly is returned when a query only model is used to modify data
+	ErrQueryOnly = errors.New("dataq: query only")
+	// ErrStopIteration stops Each() without error
+	ErrStopIteration = errors.New("dataq: stop iteration")
+	// ErrInvalidSource is returned by Open when no database source is given
+	ErrInvalidSource = errors.New("dataq: invalid database source")
+	// ErrNoSoftDelete is returned by Restore when the model has no `SOFTDELETE` field
+	ErrNoSoftDelete = errors.New("dataq: soft delete field is required")
+	// ErrStaleObject is returned by Update when the `VERSION` of a row is changed by others
+	ErrStaleObject = errors.New("dataq: stale object")
+	// ErrNotSupported is returned when the statement can not be composed with the dialect
+	ErrNotSupported = errors.New("dataq: not supported by the dialect")
+	// ErrSavepointOptions is returned by BeginTx and Transact on a transaction handler with the transaction options
+	ErrSavepointOptions = errors.New("dataq: transaction options can not be set on a savepoint")
+	// ErrUnknownTag is returned when the `dataq` tag has an unknown option
+	ErrUnknownTag = errors.New("dataq: unknown tag option")
+	// ErrNoRelation is returned by Preload when the relation is not defined or can not be matched
+	ErrNoRelation = errors.New("dataq: relation is not found")
+	// ErrVetoed is returned by Rows() when a middleware skips the query without error
+	ErrVetoed = errors.New("dataq: query vetoed by middleware")
+)
+
+// The kinds of QError, test with errors.Is or the Is* predicates
+var (
+	ErrDuplicateKey        = errors.New("dataq: duplicate key")
+	ErrForeignKeyViolation = errors.New("dataq: foreign key violation")
+	ErrDeadlock            = errors.New("dataq: deadlock")
+	ErrLockWaitTimeout     = errors.New("dataq: lock wait timeout")
+	ErrDataTooLong         = errors.New("dataq: data too long")
+)
+
+// the MySQL error numbers
+const (
+	mysqlErrLockWaitTimeout  = 1205
+	mysqlErrDeadlock         = 1213
+	mysqlErrDupEntry         = 1062
+	mysqlErrRowIsReferenced  = 1451
+	mysqlErrNoReferencedRow  = 1452
+	mysqlErrDataTooLong      = 1406
+	mysqlErrRowIsReferenced2 = 1217
+	mysqlErrNoReferencedRow2 = 1216
+)
+
+var (
+	// Duplicate entry 'a@b.c' for key 'users.email'
+	reDuplicateKey = regexp.MustCompile(`for key '([^']*)'`)
+	// ... a foreign key constraint fails (`db`.`child`, CONSTRAINT `fk_parent` FOREIGN KEY ...
+	reForeignKey = regexp.MustCompile("CONSTRAINT `([^`]*)`")
+	// Data too long for column 'name' at row 1
+	reColumn = regexp.MustCompile(`for column '([^']*)'`)
+)
+
+// QError is the classified error of the database
+type QError struct {
+	// Kind is one of ErrDuplicateKey, ErrForeignKeyViolation, ErrDeadlock, ErrLockWaitTimeout and ErrDataTooLong
+	Kind error
+	// Number is the error number of the database
+	Number uint16
+	// Name is the violated key, constraint or column if known
+	Name string
+	// Err is the error of the driver
+	Err error
+}
+
+func (e *QError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%v (%s): %v", e.Kind, e.Name, e.Err)
+	}
+
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+// Is matches the Kind
+func (e *QError) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Unwrap returns the error of the driver, errors.As(err, **mysql.MySQLError) still works
+func (e *QError) Unwrap() error {
+	return e.Err
+}
+
+// classifyError wraps the known error of the driver into *QError, the others are returned as is
+func classifyError(err error) error {
+	var qErr *QError
+	if err == nil || errors.As(err, &qErr) {
+		return err
+	}
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+
+	qErr = &QError{Number: mysqlErr.Number, Err: err}
+	switch mysqlErr.Number {
+	case mysqlErrDupEntry:
+		qErr.Kind = ErrDuplicateKey
+		qErr.Name = findName(reDuplicateKey, mysqlErr.Message)
+	case mysqlErrRowIsReferenced, mysqlErrNoReferencedRow, mysqlErrRowIsReferenced2, mysqlErrNoReferencedRow2:
+		qErr.Kind = ErrForeignKeyViolation
+		qErr.Name = findName(reForeignKey, mysqlErr.Message)
+	case mysqlErrDeadlock:
+		qErr.Kind = ErrDeadlock
+	case mysqlErrLockWaitTimeout:
+		qErr.Kind = ErrLockWaitTimeout
+	case mysqlErrDataTooLong:
+		qErr.Kind = ErrDataTooLong
+		qErr.Name = findName(reColumn, mysqlErr.Message)
+	default:
+		return err
+	}
+
+	return qErr
+}
+
+func findName(re *regexp.Regexp, message string) string {
+	if match := re.FindStringSubmatch(message); match != nil {
+		return match[1]
+	}
+
+	return ""
+}
+
+// ErrorName returns the violated key, constraint or column of the error, empty if unknown
+func ErrorName(err error) string {
+	var qErr *QError
+	if errors.As(classifyError(err), &qErr) {
+		return qErr.Name
+	}
+
+	return ""
+}
+
+// IsDuplicateKey reports whether err is a duplicate key error, the key is returned by ErrorName
+func IsDuplicateKey(err error) bool {
+	return errors.Is(classifyError(err), ErrDuplicateKey)
+}
+
+// IsForeignKeyViolation reports whether err violates a foreign key, the constraint is returned by ErrorName
+func IsForeignKeyViolation(err error) bool {
+	return errors.Is(classifyError(err), ErrForeignKeyViolation)
+}
+
+// IsDeadlock reports whether the transaction is deadlocked
+func IsDeadlock(err error) bool {
+	return errors.Is(classifyError(err), ErrDeadlock)
+}
+
+// IsLockWaitTimeout reports whether the lock wait is timeout
+func IsLockWaitTimeout(err error) bool {
+	return errors.Is(classifyError(err), ErrLockWaitTimeout)
+}
+
+// IsDataTooLong reports whether the data is too long for the column, the column is returned by ErrorName
+func IsDataTooLong(err error) bool {
+	return errors.Is(classifyError(err), ErrDataTooLong)
+}
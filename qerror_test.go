@@ -0,0 +1,46 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  *mysql.MySQLError
+		is   func(error) bool
+		kind error
+		name string
+	}{
+		{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.c' for key 'users.email'"}, IsDuplicateKey, ErrDuplicateKey, "users.email"},
+		{&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`orders`, CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"}, IsForeignKeyViolation, ErrForeignKeyViolation, "fk_orders_user"},
+		{&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock; try restarting transaction"}, IsDeadlock, ErrDeadlock, ""},
+		{&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"}, IsLockWaitTimeout, ErrLockWaitTimeout, ""},
+		{&mysql.MySQLError{Number: 1406, Message: "Data too long for column 'NAME' at row 1"}, IsDataTooLong, ErrDataTooLong, "NAME"},
+	}
+
+	for _, tt := range tests {
+		db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+			return fakeResponse{Err: tt.err}
+		})
+		res := db.Model(&batchPerson{ID: 1, Name: "Mike"}).Insert()
+		if !tt.is(res.Error) || !tt.is(tt.err) || !errors.Is(res.Error, tt.kind) {
+			t.Errorf("%d: got %v, want %v", tt.err.Number, res.Error, tt.kind)
+		}
+		if name := ErrorName(res.Error); name != tt.name {
+			t.Errorf("%d: got name %q, want %q", tt.err.Number, name, tt.name)
+		}
+		var mysqlErr *mysql.MySQLError
+		if !errors.As(res.Error, &mysqlErr) || mysqlErr != tt.err {
+			t.Errorf("%d: the driver error is not wrapped", tt.err.Number)
+		}
+	}
+
+	other := &mysql.MySQLError{Number: 1146, Message: "Table 'db.Person' doesn't exist"}
+	if err := classifyError(other); err != other || IsDuplicateKey(err) {
+		t.Fatalf("got %v, want %v", err, other)
+	}
+}
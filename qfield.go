@@ -18,10 +18,14 @@ type qField struct {
 	Init              bool
 	Self              string
 	Schema            string
-	ValIdx            int
+	ValIdx            []int
 	IsIndex           bool
 	IgnoreNull        bool
 	PassUpdate        bool
+	AutoCreate        bool
+	AutoUpdate        bool
+	SoftDelete        qSoftDelete
+	Version           bool
 }
 
 func (_f qField) String() string {
@@ -29,15 +33,20 @@ func (_f qField) String() string {
 		_f.Table, _f.ColName, _f.AsNull, _f.AsClear, _f.Alt, _f.Json, _f.JsonCast, _f.JsonMergePatch, _f.JsonArrayAppend, _f.Self, _f.Schema, _f.ValIdx, _f.IsIndex)
 }
 
-func (_f qField) SelectString() (field string) {
+// IsJSON reports whether the column is mapped by the JSON tags
+func (_f qField) IsJSON() bool {
+	return _f.Json != "" || _f.JsonCast || _f.JsonMerge != "" || _f.JsonMergePreserve != "" || _f.JsonMergePatch != "" || _f.JsonArrayAppend != ""
+}
+
+func (_f qField) SelectString(dialect Dialect) (field string) {
 	if len(_f.Table) != 0 {
-		field = fmt.Sprintf("`%s`.`%s`", _f.Table, _f.ColName)
+		field = fmt.Sprintf("%s.%s", dialect.Quote(_f.Table), dialect.Quote(_f.ColName))
 	} else {
 		field = _f.ColName
 	}
 
 	if _f.Json != "" {
-		field = fmt.Sprintf("%s->>'$.%s'", field, _f.Json)
+		field = dialect.JSONExtract(field, _f.Json)
 	}
 	return
 }
@@ -0,0 +1,126 @@
+package dataq
+
+import (
+	"reflect"
+)
+
+// The lifecycle hooks of the model, implemented by the struct (or its pointer) and invoked per row
+// The hook receives the connection (or the transaction) of the statement, an error aborts the statement
+type (
+	BeforeInsertHook interface {
+		BeforeInsert(db *QData) error
+	}
+	AfterInsertHook interface {
+		AfterInsert(db *QData) error
+	}
+	BeforeUpdateHook interface {
+		BeforeUpdate(db *QData) error
+	}
+	AfterUpdateHook interface {
+		AfterUpdate(db *QData) error
+	}
+	BeforeDeleteHook interface {
+		BeforeDelete(db *QData) error
+	}
+	AfterQueryHook interface {
+		AfterQuery(db *QData) error
+	}
+)
+
+var (
+	beforeInsertHookType = reflect.TypeOf((*BeforeInsertHook)(nil)).Elem()
+	afterInsertHookType  = reflect.TypeOf((*AfterInsertHook)(nil)).Elem()
+	beforeUpdateHookType = reflect.TypeOf((*BeforeUpdateHook)(nil)).Elem()
+	afterUpdateHookType  = reflect.TypeOf((*AfterUpdateHook)(nil)).Elem()
+	beforeDeleteHookType = reflect.TypeOf((*BeforeDeleteHook)(nil)).Elem()
+	afterQueryHookType   = reflect.TypeOf((*AfterQueryHook)(nil)).Elem()
+)
+
+// hookTypes returns the hooks of the method to be invoked before and after the statement
+func (m qMethod) hookTypes() (before, after reflect.Type) {
+	switch m {
+	case sqlInsert:
+		return beforeInsertHookType, afterInsertHookType
+	case sqlUpdate:
+		return beforeUpdateHookType, afterUpdateHookType
+	case sqlDelete:
+		return beforeDeleteHookType, nil
+	case sqlSelect:
+		return nil, afterQueryHookType
+	}
+
+	return nil, nil
+}
+
+// hasHook reports whether the element type of the model implements the hook
+func (_s *qStruct) hasHook(hookType reflect.Type) bool {
+	if hookType == nil || !_s.Value.IsValid() {
+		return false
+	}
+	elemType := _s.getElemType()
+
+	return elemType.Implements(hookType) || reflect.PointerTo(elemType).Implements(hookType)
+}
+
+// runHooks invokes the hook of the first n rows, of all the rows if n < 0
+func (stat *QStat) runHooks(hookType reflect.Type, n int) error {
+	if !stat.sqlStruct.hasHook(hookType) {
+		return nil
+	}
+	stat.sqlStruct.makeAddressable()
+
+	length := 1
+	if stat.sqlStruct.Value.Kind() == reflect.Slice {
+		length = stat.sqlStruct.Value.Len()
+	}
+	if n >= 0 && n < length {
+		length = n
+	}
+	db := stat.dbc.WithContext(stat.Context())
+	for i := 0; i < length; i++ {
+		if err := callHook(stat.sqlStruct.getRowValue(i), hookType, db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// callHook invokes the hook of the row, the row must be addressable for the pointer receiver
+func callHook(row reflect.Value, hookType reflect.Type, db *QData) error {
+	var hook any
+	if row.CanAddr() && row.Addr().Type().Implements(hookType) {
+		hook = row.Addr().Interface()
+	} else if row.Type().Implements(hookType) {
+		hook = row.Interface()
+	} else {
+		return nil
+	}
+
+	switch hookType {
+	case beforeInsertHookType:
+		return hook.(BeforeInsertHook).BeforeInsert(db)
+	case afterInsertHookType:
+		return hook.(AfterInsertHook).AfterInsert(db)
+	case beforeUpdateHookType:
+		return hook.(BeforeUpdateHook).BeforeUpdate(db)
+	case afterUpdateHookType:
+		return hook.(AfterUpdateHook).AfterUpdate(db)
+	case beforeDeleteHookType:
+		return hook.(BeforeDeleteHook).BeforeDelete(db)
+	case afterQueryHookType:
+		return hook.(AfterQueryHook).AfterQuery(db)
+	}
+
+	return nil
+}
+
+// makeAddressable replaces the struct model passed by value with an addressable copy,
+// so that the changes of the hooks are written
+func (_s *qStruct) makeAddressable() {
+	if _s.Value.Kind() == reflect.Struct && !_s.Value.CanAddr() {
+		copied := reflect.New(_s.Value.Type()).Elem()
+		copied.Set(*_s.Value)
+		_s.Value = &copied
+	}
+}
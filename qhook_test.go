@@ -0,0 +1,85 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type hookPerson struct {
+	ID   int64     `COL:"ID" TABLE:"Person" INDEX:""`
+	Name string    `COL:"NAME"`
+	log  *[]string `OMIT:""`
+}
+
+func (p *hookPerson) BeforeInsert(db *QData) error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	p.Name = strings.ToUpper(p.Name)
+	return nil
+}
+
+func (p *hookPerson) AfterInsert(db *QData) error {
+	*p.log = append(*p.log, "inserted:"+p.Name)
+	return nil
+}
+
+func (p hookPerson) BeforeDelete(db *QData) error {
+	if !db.InTx() {
+		return errors.New("delete in transaction")
+	}
+	return nil
+}
+
+func (p *hookPerson) AfterQuery(db *QData) error {
+	p.Name = strings.ToLower(p.Name)
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	var queries []string
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		queries = append(queries, query)
+		if strings.HasPrefix(strings.TrimSpace(query), "SELECT") {
+			return personRows(query, args)
+		}
+		if strings.HasPrefix(query, "INSERT") && args[1].Value != "MIKE" {
+			t.Errorf("got %v, want MIKE", args[1].Value)
+		}
+		return fakeResponse{AffectedRows: 1}
+	})
+
+	var log []string
+	if res := db.Model(hookPerson{ID: 1, Name: "Mike", log: &log}).Insert(); res.Error != nil || strings.Join(log, ",") != "inserted:MIKE" {
+		t.Fatalf("got %v, %v", res.Error, log)
+	}
+	if res := db.Model(&hookPerson{ID: 2, log: &log}).Insert(); res.Error == nil || len(queries) != 1 {
+		t.Fatalf("got %v, %d queries, want the hook error", res.Error, len(queries))
+	}
+
+	if res := db.Model(&hookPerson{ID: 1}).Delete(); res.Error == nil {
+		t.Fatal("want the hook error")
+	}
+	tx := db.Begin()
+	err := tx.FinAfterFuncOK(func() error {
+		return tx.Model(&hookPerson{ID: 1}).Delete().Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	persons := make([]hookPerson, 5)
+	if res := db.Model(&persons).Query(); res.Error != nil || persons[0].Name != "mike" || persons[2].Name != "tom" {
+		t.Fatalf("got %v, %v", res.Error, persons)
+	}
+	var names []string
+	err = Each(db.Model(&[]hookPerson{}), func(per hookPerson) error {
+		names = append(names, per.Name)
+		return nil
+	})
+	if err != nil || strings.Join(names, ",") != "mike,jane,tom" {
+		t.Fatalf("got %v, %v", names, err)
+	}
+}
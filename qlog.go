@@ -0,0 +1,138 @@
+package dataq
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// QLogEvent is the structured event of an executed statement
+type QLogEvent struct {
+	SQL string
+	// Args are the bound values, replaced by RedactedArg if Config.RedactArgs
+	Args []any
+	// Method is INSERT, SELECT, UPDATE, DELETE, COUNT, BATCH INSERT, BATCH UPDATE, RESTORE, CREATE TABLE or UNSAFE
+	Method       string
+	Table        string
+	Duration     time.Duration
+	AffectedRows int64
+	ReturnedRows int64
+	LastInsertId int64
+	Err          error
+	Prepared     bool
+	InTx         bool
+}
+
+// Logger receives an event per statement, it must be safe for concurrent use
+type Logger interface {
+	Log(ctx context.Context, event QLogEvent)
+}
+
+// LoggerFunc is the function adapter of Logger
+type LoggerFunc func(ctx context.Context, event QLogEvent)
+
+func (f LoggerFunc) Log(ctx context.Context, event QLogEvent) {
+	f(ctx, event)
+}
+
+// RedactedArg replaces the bound values when Config.RedactArgs is set
+const RedactedArg = "[REDACTED]"
+
+const methodUnsafe = "UNSAFE"
+
+func (m qMethod) String() string {
+	switch m {
+	case sqlInsert:
+		return "INSERT"
+	case sqlSelect:
+		return "SELECT"
+	case sqlUpdate:
+		return "UPDATE"
+	case sqlDelete:
+		return "DELETE"
+	case sqlCount:
+		return "COUNT"
+	case sqlBatchInsert:
+		return "BATCH INSERT"
+	case sqlBatchUpdate:
+		return "BATCH UPDATE"
+	case sqlRestore:
+		return "RESTORE"
+	case sqlCreateTable:
+		return "CREATE TABLE"
+	}
+
+	return fmt.Sprintf("qMethod(%d)", uint(m))
+}
+
+// debugLogger prints the events to stdout by Config.DebugLvl when no Logger is set
+//
+//	> 0: the result
+//	> 1: the bound values
+//	> 2: the SQL
+type debugLogger struct {
+	lvl int
+}
+
+func (l debugLogger) Log(ctx context.Context, event QLogEvent) {
+	if l.lvl > 2 {
+		fmt.Println("Model SQL: " + event.SQL)
+	}
+	if l.lvl > 1 {
+		fmt.Printf("Values %#v\n", event.Args)
+	}
+	switch {
+	case event.Err != nil:
+		fmt.Println("QResult: Error [", event.Err, "] Duration [", event.Duration, "]")
+	case event.Method == sqlSelect.String() || event.Method == sqlCount.String():
+		fmt.Println("QResult: ReturnedRows [", event.ReturnedRows, "] Duration [", event.Duration, "]")
+	default:
+		fmt.Println("QResult: AffectedRows [", event.AffectedRows, "] LastInsertID [", event.LastInsertId, "] Duration [", event.Duration, "]")
+	}
+}
+
+// logger returns Config.Logger, the debugLogger if Config.DebugLvl > 0, or nil
+func (dbc *QData) logger() Logger {
+	if dbc.config.Logger != nil {
+		return dbc.config.Logger
+	}
+	if dbc.config.DebugLvl > 0 {
+		return debugLogger{lvl: dbc.config.DebugLvl}
+	}
+
+	return nil
+}
+
+// logEvent completes the event and sends it to the logger
+func (dbc *QData) logEvent(ctx context.Context, start time.Time, event QLogEvent) {
+	logger := dbc.logger()
+	if logger == nil {
+		return
+	}
+	event.Duration = time.Since(start)
+	event.InTx = dbc.tx != nil
+	if dbc.config.RedactArgs {
+		args := make([]any, len(event.Args))
+		for _idx := range args {
+			args[_idx] = RedactedArg
+		}
+		event.Args = args
+	}
+
+	logger.Log(ctx, event)
+}
+
+// logResult logs the statement of the model with its result
+func (stat *QStat) logResult(_sql string, start time.Time, res *QResult) {
+	stat.dbc.logEvent(stat.Context(), start, QLogEvent{
+		SQL:          _sql,
+		Args:         stat.sqlStruct.Values,
+		Method:       stat.Method.String(),
+		Table:        stat.sqlStruct.Table,
+		AffectedRows: res.AffectedRows,
+		ReturnedRows: res.ReturnedRows,
+		LastInsertId: res.LastInsertId,
+		Err:          res.Error,
+		Prepared:     stat.preparedStmt,
+	})
+}
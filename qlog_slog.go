@@ -0,0 +1,44 @@
+//go:build go1.21
+
+package dataq
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLogger adapts *slog.Logger to Logger,
+// the statements are logged at Debug level, the failed ones at Error level
+type SlogLogger struct {
+	Logger *slog.Logger
+}
+
+// NewSlogLogger returns the Logger writing to l, slog.Default() if l is nil
+func NewSlogLogger(l *slog.Logger) SlogLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	return SlogLogger{Logger: l}
+}
+
+func (l SlogLogger) Log(ctx context.Context, event QLogEvent) {
+	level := slog.LevelDebug
+	attrs := []slog.Attr{
+		slog.String("sql", event.SQL),
+		slog.Any("args", event.Args),
+		slog.String("method", event.Method),
+		slog.String("table", event.Table),
+		slog.Duration("duration", event.Duration),
+		slog.Int64("affected_rows", event.AffectedRows),
+		slog.Int64("returned_rows", event.ReturnedRows),
+		slog.Bool("prepared", event.Prepared),
+		slog.Bool("in_tx", event.InTx),
+	}
+	if event.Err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.Any("error", event.Err))
+	}
+
+	l.Logger.LogAttrs(ctx, level, "dataq", attrs...)
+}
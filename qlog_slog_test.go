@@ -0,0 +1,25 @@
+//go:build go1.21
+
+package dataq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	logger.Log(context.Background(), QLogEvent{SQL: "SELECT 1", Method: "SELECT", Err: errors.New("boom")})
+
+	out := buf.String()
+	for _, want := range []string{"level=ERROR", `sql="SELECT 1"`, "method=SELECT", "error=boom"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q not in %s", want, out)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package dataq
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+)
+
+type eventRecorder struct {
+	mux    sync.Mutex
+	events []QLogEvent
+}
+
+func (r *eventRecorder) Log(ctx context.Context, event QLogEvent) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestLogger(t *testing.T) {
+	recorder := &eventRecorder{}
+	db := newFakeData(t, Config{Logger: recorder, RedactArgs: true}, func(query string, args []driver.NamedValue) fakeResponse {
+		if query == "BEGIN" || query == "COMMIT" {
+			return fakeResponse{}
+		}
+		if len(args) == 0 {
+			return personRows(query, args)
+		}
+		return fakeResponse{AffectedRows: 1, LastInsertId: 7}
+	})
+
+	tx := db.Begin()
+	if res := tx.Model(&batchPerson{Name: "Mike"}).Insert(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Each(db.Model(&[]rowsPerson{}), func(per rowsPerson) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecUnsafe("DELETE FROM Person"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorder.events) != 3 {
+		t.Fatalf("got %d events, want 3", len(recorder.events))
+	}
+	insert := recorder.events[0]
+	if insert.Method != "INSERT" || insert.Table != "Person" || !insert.InTx || insert.AffectedRows != 1 || insert.LastInsertId != 7 ||
+		len(insert.Args) != 1 || insert.Args[0] != RedactedArg || insert.Duration <= 0 {
+		t.Errorf("got insert %+v", insert)
+	}
+	if selects := recorder.events[1]; selects.Method != "SELECT" || selects.ReturnedRows != 3 || selects.InTx {
+		t.Errorf("got select %+v", selects)
+	}
+	if unsafe := recorder.events[2]; unsafe.Method != "UNSAFE" || unsafe.SQL != "DELETE FROM Person" {
+		t.Errorf("got unsafe %+v", unsafe)
+	}
+}
@@ -0,0 +1,50 @@
+package dataq
+
+import (
+	"context"
+)
+
+// QQuery is the composed statement passed through the middlewares,
+// SQL is the final statement after the Variables substitution and Args are the bound values
+type QQuery struct {
+	SQL  string
+	Args []any
+	// Method is INSERT, SELECT, UPDATE, DELETE, COUNT, BATCH INSERT, BATCH UPDATE, RESTORE or CREATE TABLE
+	Method string
+	Table  string
+}
+
+// QHandler executes the query, the context passed to the next handler is used by the statement
+type QHandler func(ctx context.Context, query *QQuery) *QResult
+
+// QMiddleware wraps the next handler,
+// it can modify the query, veto it by returning a QResult with Error without calling next, and observe the QResult
+// When next is not called, the version check, the change tracking and the after hooks of the model are skipped
+type QMiddleware func(next QHandler) QHandler
+
+// Use registers the middlewares around the statements of the models, the first one is the outermost
+// The *Unsafe functions are not covered
+func (dbc *QData) Use(middlewares ...QMiddleware) *QData {
+	dbc.middlewares = append(dbc.middlewares[:len(dbc.middlewares):len(dbc.middlewares)], middlewares...)
+
+	return dbc
+}
+
+// handle runs the query through the middlewares and then the handler
+func (stat *QStat) handle(_sql string, handler QHandler) *QResult {
+	for i := len(stat.dbc.middlewares) - 1; i >= 0; i-- {
+		handler = stat.dbc.middlewares[i](handler)
+	}
+
+	res := handler(stat.Context(), &QQuery{
+		SQL:    _sql,
+		Args:   stat.sqlStruct.Values,
+		Method: stat.Method.String(),
+		Table:  stat.sqlStruct.Table,
+	})
+	if res == nil {
+		return &QResult{}
+	}
+
+	return res
+}
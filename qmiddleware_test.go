@@ -0,0 +1,89 @@
+package dataq
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestMiddleware(t *testing.T) {
+	var queries []string
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		queries = append(queries, query)
+		if strings.HasPrefix(strings.TrimSpace(query), "SELECT") {
+			return personRows(query, args)
+		}
+		return fakeResponse{AffectedRows: int64(len(args))}
+	})
+
+	var (
+		trace     []string
+		errTenant = errors.New("tenant is required")
+	)
+	db.Use(func(next QHandler) QHandler {
+		return func(ctx context.Context, query *QQuery) *QResult {
+			trace = append(trace, "outer:"+query.Method)
+			res := next(context.WithValue(ctx, tenantKey{}, "t1"), query)
+			trace = append(trace, "outer:done")
+			return res
+		}
+	}, func(next QHandler) QHandler {
+		return func(ctx context.Context, query *QQuery) *QResult {
+			if query.Method == "DELETE" {
+				return &QResult{Error: errTenant}
+			}
+			query.SQL += " /* tenant:" + ctx.Value(tenantKey{}).(string) + " */"
+			query.Args = append(query.Args, "t1")
+			res := next(ctx, query)
+			trace = append(trace, "inner:"+query.Table)
+			return res
+		}
+	})
+
+	res := db.Model(&batchPerson{ID: 1, Name: "Mike"}).Update()
+	if res.Error != nil || res.AffectedRows != 3 {
+		t.Fatalf("got %v", res)
+	}
+	if !strings.HasSuffix(queries[0], " /* tenant:t1 */") {
+		t.Errorf("got %s", queries[0])
+	}
+	if got := strings.Join(trace, ","); got != "outer:UPDATE,inner:Person,outer:done" {
+		t.Errorf("got %s", got)
+	}
+
+	if res := db.Model(&batchPerson{ID: 1}).Delete(); !errors.Is(res.Error, errTenant) || len(queries) != 1 {
+		t.Fatalf("got %v, %d queries", res.Error, len(queries))
+	}
+
+	rows, err := db.Model(&[]rowsPerson{}).Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if !strings.HasSuffix(queries[1], " /* tenant:t1 */") {
+		t.Errorf("got %s", queries[1])
+	}
+}
+
+func TestMiddlewareVeto(t *testing.T) {
+	var queries []string
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		queries = append(queries, query)
+		return fakeResponse{AffectedRows: 1}
+	})
+	db.Use(func(next QHandler) QHandler {
+		return func(ctx context.Context, query *QQuery) *QResult {
+			// skip without error
+			return &QResult{}
+		}
+	})
+
+	per := versionedPerson{ID: 1, Name: "Mike", Version: 2}
+	if res := db.Model(&per).Update(); res.Error != nil || len(queries) != 0 || per.Version != 2 {
+		t.Fatalf("got %v, %d queries, version %d", res.Error, len(queries), per.Version)
+	}
+}
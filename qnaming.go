@@ -0,0 +1,138 @@
+package dataq
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy names the tables and the columns of the fields without `TABLE` / `COL`,
+// the Go names are used verbatim if the func is nil
+type NamingStrategy struct {
+	Table  func(name string) string
+	Column func(name string) string
+}
+
+var (
+	// SnakeCase names `UserID` as `user_id`
+	SnakeCase = &NamingStrategy{Table: toSnakeCase, Column: toSnakeCase}
+	// CamelCase names `UserID` as `userID`
+	CamelCase = &NamingStrategy{Table: toCamelCase, Column: toCamelCase}
+	// UpperCase names `UserID` as `USER_ID`
+	UpperCase = &NamingStrategy{Table: toUpperCase, Column: toUpperCase}
+)
+
+func (naming *NamingStrategy) tableName(name string) string {
+	if naming == nil || naming.Table == nil {
+		return name
+	}
+
+	return naming.Table(name)
+}
+
+func (naming *NamingStrategy) columnName(name string) string {
+	if naming == nil || naming.Column == nil {
+		return name
+	}
+
+	return naming.Column(name)
+}
+
+// toSnakeCase splits the words before the upper letters, the acronyms are kept as one word, e.g. `HTTPServer` -> `http_server`
+func toSnakeCase(name string) string {
+	var (
+		snake strings.Builder
+		runes = []rune(name)
+	)
+	for _idx, _rune := range runes {
+		if _idx > 0 && unicode.IsUpper(_rune) &&
+			(!unicode.IsUpper(runes[_idx-1]) || _idx+1 < len(runes) && unicode.IsLower(runes[_idx+1])) &&
+			runes[_idx-1] != '_' {
+			snake.WriteByte('_')
+		}
+		snake.WriteRune(unicode.ToLower(_rune))
+	}
+
+	return snake.String()
+}
+
+func toUpperCase(name string) string {
+	return strings.ToUpper(toSnakeCase(name))
+}
+
+// toCamelCase lowers the leading word, e.g. `HTTPServer` -> `httpServer`
+func toCamelCase(name string) string {
+	runes := []rune(name)
+	for _idx := 0; _idx < len(runes) && unicode.IsUpper(runes[_idx]); _idx++ {
+		if _idx > 0 && _idx+1 < len(runes) && unicode.IsLower(runes[_idx+1]) {
+			break
+		}
+		runes[_idx] = unicode.ToLower(runes[_idx])
+	}
+
+	return string(runes)
+}
+
+// knownTags are the legacy tags which can be written as the options of the `dataq` tag
+var knownTags = map[string]bool{
+	"COL": true, "TABLE": true, "TABLEAS": true, "TABLEALIAS": true, "COLAS": true, "PREFIX": true,
+	"INDEX": true, "INIT": true, "COUNTON": true, "ASNULL": true, "ASCLEAR": true, "ALT": true,
+	"WHERE": true, "JOIN": true, "RAW": true, "NOFROM": true, "SELF": true, "OMIT": true, "PASSUPDATE": true,
+	"JSON": true, "JSONCAST": true, "JSONMERGE": true, "JSONMERGEPRESERVE": true, "JSONMERGEPATCH": true, "JSONARRAYAPPEND": true,
+	"SCHEMAF": true, "SCHEMAT": true, "AUTOCREATE": true, "AUTOUPDATE": true, "SOFTDELETE": true, "VERSION": true,
+	"HASMANY": true, "HASONE": true, "BELONGSTO": true,
+}
+
+// parseTag translates the options of the `dataq` tag into the legacy tags,
+// e.g. `dataq:"col=NAME,index,json=Data.name"` -> `COL:"NAME" INDEX:"" JSON:"Data.name"`,
+// the legacy tags of the field take precedence, ErrUnknownTag is returned for the misspelled options
+func parseTag(tag reflect.StructTag) (reflect.StructTag, error) {
+	options, ok := tag.Lookup("dataq")
+	if !ok {
+		return tag, nil
+	}
+
+	legacy := strings.Builder{}
+	legacy.WriteString(string(tag))
+	for _, _option := range splitTagOptions(options) {
+		key, val, _ := strings.Cut(strings.TrimSpace(_option), "=")
+		if key == "" || key == "-" {
+			if key == "-" {
+				legacy.WriteString(` OMIT:""`)
+			}
+			continue
+		}
+		key = strings.ToUpper(key)
+		if !knownTags[key] {
+			return tag, fmt.Errorf("%w %q", ErrUnknownTag, strings.ToLower(key))
+		}
+		legacy.WriteString(" " + key + ":" + strconv.Quote(val))
+	}
+
+	return reflect.StructTag(legacy.String()), nil
+}
+
+// splitTagOptions splits the options by the commas out of the parentheses, e.g. `schemaf=DECIMAL(10,2),index`
+func splitTagOptions(options string) (ret []string) {
+	var (
+		depth int
+		start int
+	)
+	for _idx, _char := range options {
+		switch _char {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				ret = append(ret, options[start:_idx])
+				start = _idx + 1
+			}
+		}
+	}
+
+	return append(ret, options[start:])
+}
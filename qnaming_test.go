@@ -0,0 +1,67 @@
+package dataq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	tests := []struct {
+		name, snake, camel, upper string
+	}{
+		{"ID", "id", "id", "ID"},
+		{"UserID", "user_id", "userID", "USER_ID"},
+		{"HTTPServer", "http_server", "httpServer", "HTTP_SERVER"},
+		{"Created_At", "created_at", "created_At", "CREATED_AT"},
+	}
+	for _, tt := range tests {
+		if got := toSnakeCase(tt.name); got != tt.snake {
+			t.Errorf("snake %s: got %s, want %s", tt.name, got, tt.snake)
+		}
+		if got := toCamelCase(tt.name); got != tt.camel {
+			t.Errorf("camel %s: got %s, want %s", tt.name, got, tt.camel)
+		}
+		if got := toUpperCase(tt.name); got != tt.upper {
+			t.Errorf("upper %s: got %s, want %s", tt.name, got, tt.upper)
+		}
+	}
+}
+
+type namingPerson struct {
+	UserID   int64  `dataq:"index"`
+	FullName string `COL:"NAME"`
+	City     string `dataq:"col=INFO,json=INFO.city"`
+	Salary   string `dataq:"schemaf=DECIMAL(10,2),passupdate"`
+	Secret   string `dataq:"-"`
+}
+
+func TestNamingAndTag(t *testing.T) {
+	a, _ := analyseStruct(&namingPerson{}, SnakeCase)
+	if a.Table != "naming_person" || len(a.Fields) != 4 || a.Fields[0].ColName != "user_id" || !a.Fields[0].IsIndex ||
+		a.Fields[1].ColName != "NAME" || a.Fields[2].ColName != "INFO" || a.Fields[2].Json != "city" ||
+		a.Fields[3].Schema != "DECIMAL(10,2)" || !a.Fields[3].PassUpdate {
+		t.Fatalf("got %+v", a)
+	}
+
+	// the layout is cached per naming strategy
+	b, _ := analyseStruct(&namingPerson{}, nil)
+	if b.Table != "namingPerson" || b.Fields[0].ColName != "UserID" {
+		t.Fatalf("got %+v", b)
+	}
+	c, _ := analyseStruct(&namingPerson{}, UpperCase)
+	if c.Table != "NAMING_PERSON" || c.Fields[3].ColName != "SALARY" {
+		t.Fatalf("got %+v", c)
+	}
+}
+
+type typoPerson struct {
+	ID      int64  `dataq:"index"`
+	Deleted string `dataq:"softdelte"`
+}
+
+func TestUnknownTag(t *testing.T) {
+	_, err := analyseStruct(&typoPerson{}, nil)
+	if !errors.Is(err, ErrUnknownTag) || err.Error() != `dataq: unknown tag option "softdelte" of typoPerson.Deleted` {
+		t.Fatalf("got %v, want ErrUnknownTag", err)
+	}
+}
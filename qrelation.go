@@ -0,0 +1,200 @@
+package dataq
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// qRelationKind is the kind of the relation field
+type qRelationKind uint8
+
+const (
+	// HASMANY:"<foreign key of the child>", the field is a slice of the children
+	relationHasMany qRelationKind = iota + 1
+	// HASONE:"<foreign key of the child>", the field is the child struct or the pointer to it
+	relationHasOne
+	// BELONGSTO:"<foreign key of the model>", the field is the parent struct or the pointer to it
+	relationBelongsTo
+)
+
+// qRelation is the relation field of the model, it is not mapped to a column
+type qRelation struct {
+	Name       string
+	Kind       qRelationKind
+	ForeignKey string
+	ValIdx     []int
+	// ElemType is the struct type of the related model
+	ElemType reflect.Type
+}
+
+// getRelation returns the relation of the field, nil if the field has no relation tag
+func getRelation(field reflect.StructField, idx []int) *qRelation {
+	relation := qRelation{
+		Name:     field.Name,
+		ValIdx:   idx,
+		ElemType: field.Type,
+	}
+	switch {
+	case hasTag(field.Tag, "HASMANY"):
+		relation.Kind = relationHasMany
+		relation.ForeignKey = field.Tag.Get("HASMANY")
+		relation.ElemType = field.Type.Elem()
+	case hasTag(field.Tag, "HASONE"):
+		relation.Kind = relationHasOne
+		relation.ForeignKey = field.Tag.Get("HASONE")
+	case hasTag(field.Tag, "BELONGSTO"):
+		relation.Kind = relationBelongsTo
+		relation.ForeignKey = field.Tag.Get("BELONGSTO")
+	default:
+		return nil
+	}
+	if relation.ElemType.Kind() == reflect.Ptr {
+		relation.ElemType = relation.ElemType.Elem()
+	}
+
+	return &relation
+}
+
+// Preload loads the relation fields (`HASMANY`, `HASONE`, `BELONGSTO`) after Query,
+// with one `IN (...)` query per relation
+func (stat *QStat) Preload(names ...string) *QStat {
+	stat.preloads = append(stat.preloads, names...)
+
+	return stat
+}
+
+// preload loads the relations of the first n rows
+func (stat *QStat) preload(n int) error {
+	if n > stat.sqlStruct.rowCount() {
+		n = stat.sqlStruct.rowCount()
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	for _, name := range stat.preloads {
+		relation, ok := stat.sqlStruct.relation(name)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrNoRelation, name)
+		}
+		if err := stat.preloadRelation(relation, n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (_s *qStruct) relation(name string) (qRelation, bool) {
+	for _, _relation := range _s.Relations {
+		if _relation.Name == name {
+			return _relation, true
+		}
+	}
+
+	return qRelation{}, false
+}
+
+// fieldByColumn returns the field of the column
+func (_s *qStruct) fieldByColumn(col string) (qField, bool) {
+	for _, _field := range _s.Fields {
+		if _field.ColName == col && _field.Json == "" {
+			return _field, true
+		}
+	}
+
+	return qField{}, false
+}
+
+func (stat *QStat) preloadRelation(relation qRelation, n int) error {
+	var (
+		related    = reflect.New(reflect.SliceOf(relation.ElemType))
+		relatedS   qStruct
+		err        error
+		localField qField
+		ok         bool
+	)
+	if relatedS, err = analyseStruct(related.Interface(), stat.dbc.config.Naming); err != nil {
+		return err
+	}
+	relatedS.dialect = stat.sqlStruct.dialect
+
+	// the column of the model and the one of the related model to be matched
+	var remoteCol string
+	if relation.Kind == relationBelongsTo {
+		localField, ok = stat.sqlStruct.fieldByColumn(relation.ForeignKey)
+		if !relatedS.hasIndex() {
+			return fmt.Errorf("%w: %s has no primary key", ErrNoRelation, relation.ElemType.Name())
+		}
+		remoteCol = relatedS.Index[0].ColName
+	} else {
+		if stat.sqlStruct.hasIndex() {
+			localField, ok = stat.sqlStruct.Index[0], true
+		}
+		remoteCol = relation.ForeignKey
+	}
+	remoteField, remoteOk := relatedS.fieldByColumn(remoteCol)
+	if !ok || !remoteOk {
+		return fmt.Errorf("%w: %s can not be matched by %s", ErrNoRelation, relation.Name, relation.ForeignKey)
+	}
+
+	// IN (...) of the distinct keys
+	var (
+		keys    []any
+		keySeen = make(map[string]bool)
+	)
+	for i := 0; i < n; i++ {
+		key := stat.sqlStruct.getValueInterface(localField.ValIdx, i)
+		if key == nil || keySeen[fmt.Sprint(key)] {
+			continue
+		}
+		keySeen[fmt.Sprint(key)] = true
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// qualify the column only if the related model has the table
+	if remoteField.Table != "" {
+		remoteCol = remoteField.Table + "." + remoteField.ColName
+	}
+	db := stat.dbc.WithContext(stat.Context())
+	res := db.Model(related.Interface()).
+		WhereCond(In(remoteCol, keys...)).
+		Query()
+	if res.Error != nil {
+		return res.Error
+	}
+
+	// stitch the related rows by the key
+	relatedRows := related.Elem()
+	byKey := make(map[string][]reflect.Value)
+	for j := 0; j < relatedRows.Len(); j++ {
+		key := fmt.Sprint(valueToSQL(relatedRows.Index(j).FieldByIndex(remoteField.ValIdx)))
+		byKey[key] = append(byKey[key], relatedRows.Index(j))
+	}
+	for i := 0; i < n; i++ {
+		rows := byKey[fmt.Sprint(stat.sqlStruct.getValueInterface(localField.ValIdx, i))]
+		fieldValue := stat.sqlStruct.getRowValue(i).FieldByIndex(relation.ValIdx)
+		if relation.Kind == relationHasMany {
+			children := reflect.MakeSlice(fieldValue.Type(), 0, len(rows))
+			children = reflect.Append(children, rows...)
+			fieldValue.Set(children)
+			continue
+		}
+		if len(rows) == 0 {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			continue
+		}
+		if fieldValue.Kind() == reflect.Ptr {
+			_elem := reflect.New(relation.ElemType)
+			_elem.Elem().Set(rows[0])
+			fieldValue.Set(_elem)
+		} else {
+			fieldValue.Set(rows[0])
+		}
+	}
+
+	return nil
+}
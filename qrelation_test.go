@@ -0,0 +1,104 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type relationOrder struct {
+	ID       int64              `COL:"ID" TABLE:"Order" INDEX:""`
+	UserID   int64              `COL:"USER_ID"`
+	User     *relationUser      `BELONGSTO:"USER_ID"`
+	Shipment relationShipment   `HASONE:"ORDER_ID"`
+	Items    []relationShipment `OMIT:""`
+}
+
+type relationUser struct {
+	ID     int64           `COL:"ID" TABLE:"User" INDEX:""`
+	Name   string          `COL:"NAME"`
+	Orders []relationOrder `HASMANY:"USER_ID"`
+}
+
+type relationShipment struct {
+	ID      int64 `COL:"ID" TABLE:"Shipment" INDEX:""`
+	OrderID int64 `COL:"ORDER_ID"`
+}
+
+type relationNote struct {
+	ID     int64  `COL:"ID" TABLE:"Note" INDEX:""`
+	UserID int64  `COL:"USER_ID" RAW:""`
+	Text   string `COL:"TEXT"`
+}
+
+type relationAuthor struct {
+	ID    int64          `COL:"ID" TABLE:"User" INDEX:""`
+	Name  string         `COL:"NAME"`
+	Notes []relationNote `HASMANY:"USER_ID"`
+}
+
+func TestPreload(t *testing.T) {
+	var queries []string
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		queries = append(queries, query)
+		switch {
+		case strings.Contains(query, "FROM `User`"):
+			return fakeResponse{
+				Columns: []string{"ID", "NAME"},
+				Rows:    [][]driver.Value{{[]byte("1"), []byte("Mike")}, {[]byte("2"), []byte("Jane")}},
+			}
+		case strings.Contains(query, "FROM `Order`"):
+			return fakeResponse{
+				Columns: []string{"ID", "USER_ID"},
+				Rows:    [][]driver.Value{{[]byte("10"), []byte("1")}, {[]byte("11"), []byte("1")}, {[]byte("12"), []byte("2")}},
+			}
+		case strings.Contains(query, "FROM `Shipment`"):
+			return fakeResponse{
+				Columns: []string{"ID", "ORDER_ID"},
+				Rows:    [][]driver.Value{{[]byte("100"), []byte("11")}},
+			}
+		}
+		return fakeResponse{}
+	})
+
+	var users []relationUser
+	if res := db.Model(&users).Preload("Orders").Query(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if len(queries) != 2 || !strings.HasSuffix(queries[1], "WHERE (`Order`.`USER_ID` IN (?,?))") {
+		t.Fatalf("got %q", queries)
+	}
+	if len(users) != 2 || len(users[0].Orders) != 2 || len(users[1].Orders) != 1 || users[1].Orders[0].ID != 12 {
+		t.Fatalf("got %+v", users)
+	}
+
+	queries = nil
+	var orders []relationOrder
+	if res := db.Model(&orders).Preload("User", "Shipment").Query(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if len(queries) != 3 || !strings.HasSuffix(queries[1], "WHERE (`User`.`ID` IN (?,?))") {
+		t.Fatalf("got %q", queries)
+	}
+	if orders[0].User == nil || orders[0].User.Name != "Mike" || orders[2].User.Name != "Jane" {
+		t.Fatalf("got %+v", orders)
+	}
+	if orders[0].Shipment.ID != 0 || orders[1].Shipment.ID != 100 {
+		t.Fatalf("got %+v", orders)
+	}
+
+	// the column without the table is not qualified
+	queries = nil
+	var authors []relationAuthor
+	if res := db.Model(&authors).Preload("Notes").Query(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if len(queries) != 2 || !strings.HasSuffix(queries[1], "WHERE (`USER_ID` IN (?,?))") {
+		t.Fatalf("got %q", queries)
+	}
+
+	if res := db.Model(&orders).Preload("Items").Query(); !errors.Is(res.Error, ErrNoRelation) {
+		t.Fatalf("got %v, want ErrNoRelation", res.Error)
+	}
+}
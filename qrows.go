@@ -0,0 +1,153 @@
+package dataq
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// QRows is the cursor of the SELECT statement,
+// it decodes one row at a time into the element type of the model
+type QRows struct {
+	stat    *QStat
+	rawRows *sql.Rows
+	tmpDS   []any
+	values  []sql.RawBytes
+	row     reflect.Value
+	err     error
+	sql     string
+	start   time.Time
+	n       int64
+	closed  bool
+}
+
+// Rows executes the SELECT statement and returns the cursor of the result set
+// Use a slice model, e.g. Model(&[]Person{}), to iterate all the rows without LIMIT
+func (stat *QStat) Rows() (*QRows, error) {
+	if stat.err != nil {
+		return nil, stat.err
+	}
+	stat.Method = sqlSelect
+
+	_sql, err := stat.buildSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := QRows{
+		stat: stat,
+	}
+	rows.tmpDS, rows.values = stat.scanDest()
+	// the middlewares observe the QResult when the cursor is opened
+	res := stat.handle(_sql, func(ctx context.Context, query *QQuery) *QResult {
+		stat.ctx = ctx
+		stat.sqlStruct.Values = query.Args
+		rows.sql = query.SQL
+		rows.start = time.Now()
+		rawRows, err := stat.queryRows(query.SQL)
+		if err != nil {
+			err = classifyError(err)
+			stat.logResult(query.SQL, rows.start, &QResult{Error: err})
+			return &QResult{Error: err}
+		}
+		rows.rawRows = rawRows
+
+		return &QResult{}
+	})
+	if res.Error != nil {
+		if rows.rawRows != nil {
+			rows.rawRows.Close()
+		}
+		return nil, res.Error
+	}
+	if rows.rawRows == nil {
+		// vetoed without error
+		return nil, ErrVetoed
+	}
+
+	return &rows, nil
+}
+
+// Next decodes the next row, it returns false at the end of the result set or on error
+func (rows *QRows) Next() bool {
+	if rows.err != nil {
+		return false
+	}
+	if !rows.rawRows.Next() {
+		rows.err = classifyError(rows.rawRows.Err())
+		return false
+	}
+	if err := rows.rawRows.Scan(rows.tmpDS...); err != nil {
+		rows.err = classifyError(err)
+		return false
+	}
+	rows.row, rows.err = rows.stat.decodeRow(rows.values)
+	if rows.err == nil && rows.stat.sqlStruct.hasHook(afterQueryHookType) {
+		rows.err = callHook(rows.row, afterQueryHookType, rows.stat.dbc.WithContext(rows.stat.Context()))
+	}
+	if rows.err != nil {
+		return false
+	}
+	rows.n++
+
+	return true
+}
+
+// Scan copies the current row into dest, dest must be a pointer to the element type of the model
+func (rows *QRows) Scan(dest any) error {
+	if !rows.row.IsValid() {
+		return errors.New("dataq: Scan called without calling Next")
+	}
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Type() != rows.row.Type() {
+		return fmt.Errorf("dataq: Scan expects *%s, got %T", rows.row.Type(), dest)
+	}
+	destValue.Elem().Set(rows.row)
+
+	return nil
+}
+
+// Err returns the error occurred during the iteration
+func (rows *QRows) Err() error {
+	return rows.err
+}
+
+// Close closes the cursor, it is safe to call it more than once
+// The statement is logged at the first Close with the rows returned
+func (rows *QRows) Close() error {
+	err := rows.rawRows.Close()
+	if !rows.closed {
+		rows.closed = true
+		rows.stat.logResult(rows.sql, rows.start, &QResult{ReturnedRows: rows.n, Error: rows.err})
+	}
+
+	return err
+}
+
+// Each calls fn with each row of the SELECT statement,
+// return ErrStopIteration from fn to stop without error
+func Each[T any](stat *QStat, fn func(row T) error) error {
+	rows, err := stat.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err = rows.Scan(&row); err != nil {
+			return err
+		}
+		if err = fn(row); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return rows.Err()
+}
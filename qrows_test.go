@@ -0,0 +1,109 @@
+package dataq
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type rowsPerson struct {
+	ID   int64  `COL:"ID" TABLE:"Person"`
+	Name string `COL:"NAME"`
+}
+
+func personRows(query string, args []driver.NamedValue) fakeResponse {
+	return fakeResponse{
+		Columns: []string{"ID", "NAME"},
+		Rows: [][]driver.Value{
+			{[]byte("1"), []byte("Mike")},
+			{[]byte("2"), []byte("Jane")},
+			{[]byte("3"), []byte("Tom")},
+		},
+	}
+}
+
+func TestEach(t *testing.T) {
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		if strings.Contains(query, "LIMIT") {
+			t.Errorf("unexpected LIMIT: %s", query)
+		}
+		return personRows(query, args)
+	})
+
+	var names []string
+	err := Each(db.Model(&[]rowsPerson{}), func(per rowsPerson) error {
+		names = append(names, per.Name)
+		if per.ID == 2 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil || strings.Join(names, ",") != "Mike,Jane" {
+		t.Fatalf("got %v, %v", names, err)
+	}
+
+	rows, err := db.Model(&[]rowsPerson{}).Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var n int
+	for rows.Next() {
+		var per rowsPerson
+		if err := rows.Scan(&per); err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if rows.Err() != nil || n != 3 {
+		t.Fatalf("got %d rows, %v", n, rows.Err())
+	}
+}
+
+func TestEachDecodeError(t *testing.T) {
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		return fakeResponse{
+			Columns: []string{"ID", "NAME"},
+			Rows:    [][]driver.Value{{[]byte("x"), []byte("Mike")}},
+		}
+	})
+
+	var decodeErr *DecodeError
+	err := Each(db.Model(&[]rowsPerson{}), func(per rowsPerson) error { return nil })
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Each(db.Model(&[]rowsPerson{}).WithContext(ctx), func(per rowsPerson) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestEachDeadlock(t *testing.T) {
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		res := personRows(query, args)
+		res.RowsErr = &mysql.MySQLError{Number: mysqlErrDeadlock, Message: "Deadlock found"}
+		return res
+	})
+
+	n := 0
+	err := Each(db.Model(&[]rowsPerson{}), func(per rowsPerson) error {
+		n++
+		return nil
+	})
+	var qErr *QError
+	if n != 3 || !IsDeadlock(err) || !errors.As(err, &qErr) || !isRetryable(err) {
+		t.Fatalf("got %d rows, %v", n, err)
+	}
+
+	if res := db.Model(&[]rowsPerson{}).Query(); !errors.As(res.Error, &qErr) || !IsDeadlock(res.Error) {
+		t.Fatalf("got %v", res.Error)
+	}
+}
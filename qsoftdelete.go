@@ -0,0 +1,147 @@
+package dataq
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// qSoftDelete is the type of the `SOFTDELETE` field
+type qSoftDelete uint8
+
+const (
+	softDeleteNone qSoftDelete = iota
+	// the deleted rows have the deletion time, NULL otherwise
+	softDeleteTime
+	// the deleted rows are TRUE
+	softDeleteBool
+)
+
+// qTrashed is the scope of the soft deleted rows in SELECT, COUNT, DELETE and RESTORE
+type qTrashed uint8
+
+const (
+	trashedExclude qTrashed = iota
+	trashedWith
+	trashedOnly
+)
+
+func getSoftDelete(field reflect.StructField) qSoftDelete {
+	if !hasTag(field.Tag, "SOFTDELETE") {
+		return softDeleteNone
+	}
+	if field.Type.Kind() == reflect.Bool {
+		return softDeleteBool
+	}
+
+	return softDeleteTime
+}
+
+// softDeleteField returns the `SOFTDELETE` field of the model
+func (_s *qStruct) softDeleteField() (qField, bool) {
+	for _, _field := range _s.Fields {
+		if _field.SoftDelete != softDeleteNone {
+			return _field, true
+		}
+	}
+
+	return qField{}, false
+}
+
+// softDeleteCondition returns the condition of the trashed scope, empty if the model has no `SOFTDELETE` field,
+// the column is qualified by the `TABLEALIAS` only if aliased, as UPDATE and DELETE do not declare it
+func (_s *qStruct) softDeleteCondition(aliased bool) string {
+	_field, ok := _s.softDeleteField()
+	if !ok || _s.trashed == trashedWith {
+		return ""
+	}
+
+	col := _s.quote(_field.ColName)
+	if aliased && _s.TableAlias != "" {
+		col = fmt.Sprintf("%s.%s", _s.quote(_s.TableAlias), col)
+	} else if _s.Table != "" {
+		col = fmt.Sprintf("%s.%s", _s.quote(_s.Table), col)
+	}
+
+	switch {
+	case _field.SoftDelete == softDeleteBool && _s.trashed == trashedOnly:
+		return fmt.Sprintf("%s IS TRUE", col)
+	case _field.SoftDelete == softDeleteBool:
+		// NULL is not deleted
+		return fmt.Sprintf("%s IS NOT TRUE", col)
+	case _s.trashed == trashedOnly:
+		return fmt.Sprintf("%s IS NOT NULL", col)
+	}
+
+	return fmt.Sprintf("%s IS NULL", col)
+}
+
+// composeSoftDeleteSQL marks the rows as deleted at now
+// UPDATE table SET deleted = ? WHERE <condition> AND deleted IS NULL
+func (_s *qStruct) composeSoftDeleteSQL(filters []qClause, now any) string {
+	_field, _ := _s.softDeleteField()
+	_s.trashed = trashedExclude
+	if _field.SoftDelete == softDeleteBool {
+		return _s.composeTrashSQL(filters, "TRUE")
+	}
+	_s.Values = append(_s.Values, now)
+
+	return _s.composeTrashSQL(filters, "?")
+}
+
+// composeRestoreSQL unmarks the soft deleted rows
+// UPDATE table SET deleted = NULL WHERE <condition> AND deleted IS NOT NULL
+func (_s *qStruct) composeRestoreSQL(filters []qClause) string {
+	_field, _ := _s.softDeleteField()
+	_s.trashed = trashedOnly
+	if _field.SoftDelete == softDeleteBool {
+		return _s.composeTrashSQL(filters, "FALSE")
+	}
+
+	return _s.composeTrashSQL(filters, "NULL")
+}
+
+func (_s *qStruct) composeTrashSQL(filters []qClause, value string) string {
+	_field, _ := _s.softDeleteField()
+	sql := fmt.Sprintf("UPDATE %s SET %s = %s", _s.quote(_s.Table), _s.quote(_field.ColName), value)
+
+	condition := _s.composeWhereCondition(filters, false)
+	if len(condition) > 0 {
+		sql += fmt.Sprintf(" WHERE %s", condition)
+	}
+
+	return sql
+}
+
+// WithTrashed includes the soft deleted rows in Query and Count
+func (stat *QStat) WithTrashed() *QStat {
+	stat.trashed = trashedWith
+
+	return stat
+}
+
+// OnlyTrashed queries and counts the soft deleted rows only
+func (stat *QStat) OnlyTrashed() *QStat {
+	stat.trashed = trashedOnly
+
+	return stat
+}
+
+// Unscoped ignores the `SOFTDELETE` field: the soft deleted rows are queried, and Delete removes the rows
+func (stat *QStat) Unscoped() *QStat {
+	stat.trashed = trashedWith
+	stat.unscoped = true
+
+	return stat
+}
+
+// ForceDelete removes the rows even if the model has the `SOFTDELETE` field
+func (stat *QStat) ForceDelete() *QResult {
+	return stat.Unscoped().Delete()
+}
+
+// Restore unmarks the soft deleted rows
+func (stat *QStat) Restore() *QResult {
+	stat.Method = sqlRestore
+
+	return stat.Exec()
+}
@@ -0,0 +1,86 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+type trashPerson struct {
+	ID      int64      `COL:"ID" TABLE:"Person" INDEX:""`
+	Name    string     `COL:"NAME"`
+	Deleted *time.Time `COL:"DELETED" SOFTDELETE:""`
+}
+
+type flagPerson struct {
+	ID      int64 `COL:"ID" TABLE:"Person" INDEX:""`
+	Removed bool  `COL:"REMOVED" SOFTDELETE:""`
+}
+
+type aliasPerson struct {
+	ID      int64      `COL:"ID" TABLE:"Person" TABLEALIAS:"p" INDEX:""`
+	Deleted *time.Time `COL:"DELETED" SOFTDELETE:""`
+}
+
+func TestSoftDelete(t *testing.T) {
+	var (
+		query string
+		args  []any
+	)
+	db := newFakeData(t, Config{Clock: func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }}, func(q string, a []driver.NamedValue) fakeResponse {
+		query, args = q, nil
+		for _, arg := range a {
+			args = append(args, arg.Value)
+		}
+		return fakeResponse{Columns: []string{"COUNT"}, Rows: [][]driver.Value{{[]byte("1")}}, AffectedRows: 1}
+	})
+
+	tests := []struct {
+		name  string
+		exec  func() *QResult
+		query string
+		args  []any
+	}{
+		{"delete", func() *QResult { return db.Model(&trashPerson{ID: 1}).Delete() },
+			"UPDATE `Person` SET `DELETED` = ? WHERE (`Person`.`ID` IN (?)) AND (`Person`.`DELETED` IS NULL)", []any{"2024-01-02 03:04:05.000", int64(1)}},
+		{"force delete", func() *QResult { return db.Model(&trashPerson{ID: 1}).ForceDelete() },
+			"DELETE FROM `Person` WHERE (`Person`.`ID` IN (?))", []any{int64(1)}},
+		{"restore", func() *QResult { return db.Model(&trashPerson{ID: 1}).Restore() },
+			"UPDATE `Person` SET `DELETED` = NULL WHERE (`Person`.`ID` IN (?)) AND (`Person`.`DELETED` IS NOT NULL)", []any{int64(1)}},
+		{"count", func() *QResult { return db.Model(&[]trashPerson{}).Count() },
+			"SELECT COUNT(1) FROM `Person` WHERE (`Person`.`DELETED` IS NULL)", nil},
+		{"count with trashed", func() *QResult { return db.Model(&[]trashPerson{}).WithTrashed().Count() },
+			"SELECT COUNT(1) FROM `Person`", nil},
+		{"count only trashed", func() *QResult { return db.Model(&[]trashPerson{}).OnlyTrashed().Count() },
+			"SELECT COUNT(1) FROM `Person` WHERE (`Person`.`DELETED` IS NOT NULL)", nil},
+		{"bool delete", func() *QResult { return db.Model(&flagPerson{ID: 2}).Delete() },
+			"UPDATE `Person` SET `REMOVED` = TRUE WHERE (`Person`.`ID` IN (?)) AND (`Person`.`REMOVED` IS NOT TRUE)", []any{int64(2)}},
+		{"update trashed", func() *QResult { return db.Model(&trashPerson{ID: 3, Name: "Mike"}).Update() },
+			"UPDATE `Person` SET `NAME`=? WHERE (`ID`=?)", []any{"Mike", int64(3)}},
+		{"update trashed rows", func() *QResult {
+			return db.Model(&[]trashPerson{{ID: 3, Name: "Mike"}, {ID: 4, Name: "Jane"}}).Update()
+		}, "UPDATE `Person` SET `NAME`=CASE `ID` WHEN ? THEN ? WHEN ? THEN ? ELSE `NAME` END WHERE `ID` IN (?,?)", []any{int64(3), "Mike", int64(4), "Jane", int64(3), int64(4)}},
+		{"alias delete", func() *QResult { return db.Model(&aliasPerson{ID: 5}).Delete() },
+			"UPDATE `Person` SET `DELETED` = ? WHERE (`Person`.`ID` IN (?)) AND (`Person`.`DELETED` IS NULL)", []any{"2024-01-02 03:04:05.000", int64(5)}},
+		{"alias restore", func() *QResult { return db.Model(&aliasPerson{ID: 5}).Restore() },
+			"UPDATE `Person` SET `DELETED` = NULL WHERE (`Person`.`ID` IN (?)) AND (`Person`.`DELETED` IS NOT NULL)", []any{int64(5)}},
+		{"alias count", func() *QResult { return db.Model(&[]aliasPerson{}).Count() },
+			"SELECT COUNT(1) FROM `Person` AS `p` WHERE (`p`.`DELETED` IS NULL)", nil},
+		{"bool restore", func() *QResult { return db.Model(&flagPerson{ID: 2}).Restore() },
+			"UPDATE `Person` SET `REMOVED` = FALSE WHERE (`Person`.`ID` IN (?)) AND (`Person`.`REMOVED` IS TRUE)", []any{int64(2)}},
+	}
+	for _, tt := range tests {
+		if res := tt.exec(); res.Error != nil {
+			t.Errorf("%s: %v", tt.name, res.Error)
+			continue
+		}
+		if query != tt.query || len(args) != len(tt.args) || len(args) > 0 && args[0] != tt.args[0] {
+			t.Errorf("%s: got %s %v, want %s %v", tt.name, query, args, tt.query, tt.args)
+		}
+	}
+
+	if res := db.Model(&batchPerson{ID: 1}).Restore(); !errors.Is(res.Error, ErrNoSoftDelete) {
+		t.Fatalf("got %v, want %v", res.Error, ErrNoSoftDelete)
+	}
+}
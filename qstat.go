@@ -1,12 +1,10 @@
 package dataq
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -15,6 +13,9 @@ import (
 // Support multiple value states
 type QStat struct {
 	dbc          *QData
+	ctx          context.Context
+	err          error
+	looseDecode  bool
 	preparedStmt bool
 	sqlStruct    qStruct
 	Variables    map[string]string
@@ -27,6 +28,10 @@ type QStat struct {
 	BeginOffset  int
 	BatchMode    bool
 	LockFor      string
+	trashed      qTrashed
+	unscoped     bool
+	preloads     []string
+	unions       []qUnion
 }
 
 // qMethod is the basic method type
@@ -39,6 +44,7 @@ const sqlDelete qMethod = 3
 const sqlCount qMethod = 4
 const sqlBatchInsert qMethod = 5
 const sqlBatchUpdate qMethod = 6
+const sqlRestore qMethod = 7
 const sqlCreateTable qMethod = 100
 const LockForShare = "SHARE"
 const LockForUpdate = "UPDATE"
@@ -53,6 +59,22 @@ func (stat *QStat) String() string {
 	return fmt.Sprintf("Query Statement {\n\tMethod:\t%v\n\tsqlStruct:\t%v\n\tGroup:\t%v\n\tHaving:\t%v\n\tOrder:\t%v\nRowCount:\t%v\nOffset:\t%v\n}\n", stat.Method, stat.sqlStruct, stat.GroupS, stat.HavingS, stat.OrderS, stat.RowLimit, stat.BeginOffset)
 }
 
+// WithContext binds ctx to the statement, it overrides the context of the connection
+func (stat *QStat) WithContext(ctx context.Context) *QStat {
+	stat.ctx = ctx
+
+	return stat
+}
+
+// Context returns the context used to execute the statement
+func (stat *QStat) Context() context.Context {
+	if stat.ctx != nil {
+		return stat.ctx
+	}
+
+	return stat.dbc.Context()
+}
+
 // PrepareNext will prepare the next sql query
 func (stat *QStat) PrepareNext(it bool) *QStat {
 	stat.preparedStmt = it
@@ -60,6 +82,14 @@ func (stat *QStat) PrepareNext(it bool) *QStat {
 	return stat
 }
 
+// StrictDecode reports the scan and conversion errors of Query() in QResult.Error,
+// with false the failed fields are set to the zero value, default to !Config.LooseDecode
+func (stat *QStat) StrictDecode(it bool) *QStat {
+	stat.looseDecode = !it
+
+	return stat
+}
+
 func (stat *QStat) FreeLength(it bool) *QStat {
 	stat.sqlStruct.freeLength = it
 
@@ -117,7 +147,7 @@ func (stat *QStat) Join(joins ...string) *QStat {
 }
 
 // Where sets the conditions for the query
-// It supports multiple values for the same field using the `,,,` placeholder
+// It supports multiple values for the same field using the `,,,,` placeholder
 func (stat *QStat) Where(operator, template string, vals ...any) *QStat {
 	if strings.Contains(template, ",,,,") {
 		_values := make([]string, len(vals))
@@ -136,6 +166,28 @@ func (stat *QStat) Where(operator, template string, vals ...any) *QStat {
 	return stat
 }
 
+// WhereCond sets the conditions built by Eq, In, And, Or, ..., they are joined with AND:
+//
+//	WhereCond(Or(Eq("NAME", "Mike"), Gt("AGE", 30)), IsNull("DELETED_AT"))
+func (stat *QStat) WhereCond(conds ...QCond) *QStat {
+	template, vals, err := And(conds...).build(stat.sqlStruct.getDialect())
+	if err != nil {
+		if stat.err == nil {
+			stat.err = err
+		}
+		return stat
+	}
+	if template != "" {
+		stat.Filters = append(stat.Filters, qClause{
+			Operator: "AND",
+			Template: template,
+			Values:   vals,
+		})
+	}
+
+	return stat
+}
+
 func (stat *QStat) Set(template string, vals ...any) *QStat {
 	stat.sqlStruct.Sets = append(stat.sqlStruct.Sets, qClause{
 		Template: template,
@@ -255,18 +307,20 @@ func (s *QStat) ComposeSelectSQL() string {
 	return s.sqlStruct.composeSelectSQL(s.Filters)
 }
 
+// Err returns the error occurred while building the statement
+func (stat *QStat) Err() error {
+	return stat.err
+}
+
 // SetModel will only analyse the model without query to database
+// The error is kept by the statement and returned by Exec() in QResult.Error
 func (stat *QStat) SetModel(model any) *QStat {
-	sqlStruct, err := analyseStruct(model)
+	sqlStruct, err := analyseStruct(model, stat.dbc.config.Naming)
 	stat.sqlStruct = sqlStruct
 	stat.Variables = map[string]string{}
 	stat.Variables["$T0"] = stat.sqlStruct.Table
-	panicErrHandle(err)
-
-	if stat.dbc.config.DebugLvl > 3 {
-		fmt.Println("=== Init Model Struct ===")
-		fmt.Println(stat.sqlStruct)
-	}
+	stat.sqlStruct.dialect = stat.dbc.config.Dialect
+	stat.err = err
 
 	return stat
 }
@@ -279,23 +333,72 @@ func (stat *QStat) Model(model any) *QStat {
 }
 
 // Exec the query
+// The hooks of the model are invoked per row before and after the statement, see BeforeInsertHook, ...
+// The known errors of the driver are classified as *QError, see IsDuplicateKey, ...
 func (stat *QStat) Exec() *QResult {
+	if stat.err != nil {
+		return &QResult{
+			Error: stat.err,
+		}
+	}
 
-	_sql := stat.composeSQL()
-
-	for _replace, _new := range stat.Variables {
-		_sql = strings.ReplaceAll(_sql, fmt.Sprintf("`%s`", _replace), _replace)
-		_sql = strings.ReplaceAll(_sql, _replace, fmt.Sprintf("`%s`", _new))
+	beforeHook, afterHook := stat.Method.hookTypes()
+	if err := stat.runHooks(beforeHook, -1); err != nil {
+		return &QResult{
+			Error: err,
+		}
+	}
+	stat.fillTimestamps()
+	if _, ok := stat.sqlStruct.versionField(); ok && stat.Method == sqlUpdate {
+		// the versions are incremented after the update
+		stat.sqlStruct.makeAddressable()
 	}
 
-	if stat.dbc.config.DebugLvl > 2 {
-		fmt.Println("Model SQL: " + _sql)
+	_sql, err := stat.buildSQL()
+	if err != nil {
+		return &QResult{
+			Error: err,
+		}
 	}
 
-	if stat.dbc.config.DebugLvl > 1 {
-		fmt.Printf("Values %#v\n", stat.sqlStruct.Values)
+	executed := false
+	res := stat.handle(_sql, func(ctx context.Context, query *QQuery) *QResult {
+		executed = true
+		stat.ctx = ctx
+		stat.sqlStruct.Values = query.Args
+		start := time.Now()
+		res := stat.exec(query.SQL)
+		res.Error = classifyError(res.Error)
+		stat.logResult(query.SQL, start, res)
+
+		return res
+	})
+	if !executed {
+		// vetoed by a middleware, the model is left as it is
+		return res
+	}
+	if res.Error == nil && stat.Method == sqlUpdate && _sql != "" {
+		res.Error = stat.checkVersion(res)
+	}
+	if res.Error == nil {
+		stat.updateSnapshot(res)
+	}
+	if res.Error == nil && stat.Method == sqlSelect && len(stat.preloads) > 0 {
+		res.Error = stat.preload(int(res.ReturnedRows))
+	}
+	if res.Error == nil {
+		n := -1
+		if stat.Method == sqlSelect {
+			n = int(res.ReturnedRows)
+		}
+		res.Error = stat.runHooks(afterHook, n)
 	}
 
+	return res
+}
+
+// exec runs the composed statement
+func (stat *QStat) exec(_sql string) *QResult {
 	switch stat.Method {
 	case sqlBatchInsert:
 		fallthrough
@@ -305,10 +408,12 @@ func (stat *QStat) Exec() *QResult {
 		fallthrough
 	case sqlDelete:
 		fallthrough
+	case sqlRestore:
+		fallthrough
 	case sqlUpdate:
 		if stat.sqlStruct.QueryOnly {
 			return &QResult{
-				Error: errors.New("dataq: query only"),
+				Error: ErrQueryOnly,
 			}
 		}
 
@@ -329,7 +434,7 @@ func (stat *QStat) Exec() *QResult {
 			}
 			// defer preparedStmt.Close()
 
-			rawResult, err = preparedStmt.Exec(stat.sqlStruct.Values...)
+			rawResult, err = preparedStmt.ExecContext(stat.Context(), stat.sqlStruct.Values...)
 			if err != nil {
 				return &QResult{
 					Error: err,
@@ -355,15 +460,8 @@ func (stat *QStat) Exec() *QResult {
 		//   affectedRows = int64(stat.sqlStruct.Length)
 		// }
 
-		lastInsertID, err := rawResult.LastInsertId()
-		if err != nil {
-			return &QResult{
-				Error: err,
-			}
-		}
-		if stat.dbc.config.DebugLvl > 0 {
-			fmt.Println("QResult: AffectedRows [", affectedRows, "] LastInsertID [", lastInsertID, "]")
-		}
+		// the PostgreSQL drivers do not support LastInsertId, use RETURNING instead
+		lastInsertID, _ := rawResult.LastInsertId()
 
 		return &QResult{
 			AffectedRows: affectedRows,
@@ -372,44 +470,16 @@ func (stat *QStat) Exec() *QResult {
 	case sqlSelect:
 		if stat.sqlStruct.Value.Kind() != reflect.Slice && !stat.sqlStruct.Value.CanSet() {
 			return &QResult{
-				Error: errors.New("dataq: This struct is not settable, use new() to init. an empty struct"),
+				Error: fmt.Errorf("%w, use new() to init. an empty struct", ErrNotSettable),
 			}
 		}
 
-		var (
-			nField  = len(stat.sqlStruct.Fields)
-			tmpDS   []any
-			rawRows *sql.Rows
-		)
+		tmpDS, values := stat.scanDest()
 
-		tmpDS = make([]any, nField)
-		values := make([]sql.RawBytes, nField)
-		for i := 0; i < nField; i++ {
-			tmpDS[i] = &values[i]
-		}
-
-		if stat.preparedStmt {
-			preparedStmt, err := stat.sqlPrepare(_sql)
-			if err != nil {
-				return &QResult{
-					Error: err,
-				}
-			}
-			// defer preparedStmt.Close()
-
-			rawRows, err = preparedStmt.Query(stat.sqlStruct.Values...)
-			if err != nil {
-				return &QResult{
-					Error: err,
-				}
-			}
-		} else {
-			var err error
-			rawRows, err = stat.sqlQuery(_sql, stat.sqlStruct.Values...)
-			if err != nil {
-				return &QResult{
-					Error: err,
-				}
+		rawRows, err := stat.queryRows(_sql)
+		if err != nil {
+			return &QResult{
+				Error: err,
 			}
 		}
 		defer rawRows.Close()
@@ -424,118 +494,21 @@ func (stat *QStat) Exec() *QResult {
 		}
 
 		for rawRows.Next() {
-			rawRows.Scan(tmpDS...)
-
-			rowValue = reflect.New(stat.sqlStruct.getElemType()).Elem()
-
-			for i := range stat.sqlStruct.Fields {
-				switch rowValue.Field(i).Kind() {
-				case reflect.Bool:
-					boolVal, err := strconv.ParseBool(string(values[i]))
-					if err != nil {
-						boolVal = false
-					}
-					rowValue.Field(i).SetBool(boolVal)
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-					i64, err := strconv.ParseInt(string(values[i]), 10, rowValue.Field(i).Type().Bits())
-					if err != nil {
-						i64 = 0
-					}
-					rowValue.Field(i).SetInt(i64)
-				case reflect.Float32, reflect.Float64:
-					f64, err := strconv.ParseFloat(string(values[i]), rowValue.Field(i).Type().Bits())
-					if err != nil {
-						f64 = 0.0
-					}
-					rowValue.Field(i).SetFloat(f64)
-				case reflect.String:
-					if values[i] == nil {
-						rowValue.Field(i).SetString("")
-					} else {
-						rowValue.Field(i).SetString(string(values[i]))
-					}
-				case reflect.Struct:
-					// TODO: not only parse Time
-					_type := rowValue.Field(i).Type()
-					switch _type.PkgPath() {
-					case "time":
-						// Need timezone and can be parsed by Javascript
-						t, _ := time.Parse(ConfigParseDateTimeFormat, string(values[i]))
-						rowValue.Field(i).Set(reflect.ValueOf(t))
-					case "github.com/collatzc/dataq":
-						switch _type.Name() {
-						case "QBool":
-							boolVal, err := strconv.ParseBool(string(values[i]))
-							if err != nil {
-								boolVal = false
-							}
-							rowValue.Field(i).Set(reflect.ValueOf(QBool{
-								Valid: true,
-								Value: boolVal,
-							}))
-						case "QInt":
-							intVal, err := strconv.Atoi(string(values[i]))
-							if err != nil {
-								intVal = 0
-							}
-							rowValue.Field(i).Set(reflect.ValueOf(QInt{
-								Valid: true,
-								Value: intVal,
-							}))
-						case "QFloat64":
-							f64, err := strconv.ParseFloat(string(values[i]), 64)
-							if err != nil {
-								f64 = 0.0
-							}
-							rowValue.Field(i).Set(reflect.ValueOf(QFloat64{
-								Valid: true,
-								Value: f64,
-							}))
-						case "QString":
-							rowValue.Field(i).Set(reflect.ValueOf(QString{
-								Valid: true,
-								Value: string(values[i]),
-							}))
-						case "QStrings":
-							if len(values[i]) > 0 {
-								var _ValueSlice = make([]string, len(values[i]))
-								json.Unmarshal(values[i], &_ValueSlice)
-								rowValue.Field(i).Set(reflect.ValueOf(QStrings{
-									Valid: true,
-									Value: _ValueSlice,
-								}))
-							} else {
-								rowValue.Field(i).Set(reflect.ValueOf(QStrings{
-									Valid: false,
-									Value: []string{},
-								}))
-							}
-						case "QTime":
-							t, _ := time.Parse(ConfigParseDateTimeFormat, string(values[i]))
-							rowValue.Field(i).Set(reflect.ValueOf(QTime{
-								Valid: true,
-								Value: t,
-							}))
-						}
-					default:
-						var _ValueStruct = reflect.New(rowValue.Field(i).Type())
-						json.Unmarshal(values[i], _ValueStruct.Interface())
-						rowValue.Field(i).Set(_ValueStruct.Elem())
-					}
-				case reflect.Map:
-					var _map map[string]any
-					json.Unmarshal(values[i], &_map)
-					rowValue.Field(i).Set(reflect.ValueOf(_map))
-				case reflect.Slice:
-					if len(values[i]) > 0 {
-						var _ValueSlice = reflect.New(rowValue.Field(i).Type())
-						json.Unmarshal(values[i], _ValueSlice.Interface())
-						rowValue.Field(i).Set(_ValueSlice.Elem())
-					} else {
-						rowValue.Field(i).Set(reflect.MakeSlice(rowValue.Field(i).Type(), 0, 0))
-					}
+			if err := rawRows.Scan(tmpDS...); err != nil {
+				return &QResult{
+					ReturnedRows: int64(rowNumber),
+					Error:        err,
+				}
+			}
+
+			rowValue, err = stat.decodeRow(values)
+			if err != nil {
+				return &QResult{
+					ReturnedRows: int64(rowNumber),
+					Error:        err,
 				}
 			}
+
 			if stat.sqlStruct.freeLength {
 				stat.sqlStruct.Value.Set(reflect.Append(*stat.sqlStruct.Value, rowValue))
 			} else {
@@ -544,10 +517,13 @@ func (stat *QStat) Exec() *QResult {
 
 			rowNumber++
 		}
-
-		if stat.dbc.config.DebugLvl > 0 {
-			fmt.Println("QResult: ReturnedRows [", rowNumber, "]")
+		if err := rawRows.Err(); err != nil {
+			return &QResult{
+				ReturnedRows: int64(rowNumber),
+				Error:        err,
+			}
 		}
+
 		return &QResult{
 			ReturnedRows: int64(rowNumber),
 		}
@@ -561,9 +537,9 @@ func (stat *QStat) Exec() *QResult {
 			}
 			// defer preparedStmt.Close()
 
-			preparedStmt.QueryRow(stat.sqlStruct.Values...).Scan(&res.ReturnedRows)
+			res.Error = preparedStmt.QueryRowContext(stat.Context(), stat.sqlStruct.Values...).Scan(&res.ReturnedRows)
 		} else {
-			stat.QueryRowUnsafe(_sql, stat.sqlStruct.Values...).Scan(&res.ReturnedRows)
+			res.Error = stat.sqlQueryRow(_sql, stat.sqlStruct.Values...).Scan(&res.ReturnedRows)
 		}
 
 		return &res
@@ -582,12 +558,8 @@ func (stat *QStat) Exec() *QResult {
 			}
 		}
 
-		lastInsertID, err := rawResult.LastInsertId()
-		if err != nil {
-			return &QResult{
-				Error: err,
-			}
-		}
+		// the PostgreSQL drivers do not support LastInsertId, use RETURNING instead
+		lastInsertID, _ := rawResult.LastInsertId()
 
 		return &QResult{
 			LastInsertId: lastInsertID,
@@ -598,29 +570,87 @@ func (stat *QStat) Exec() *QResult {
 	return &QResult{}
 }
 
-func (stat *QStat) composeSQL() string {
+// buildSQL composes the statement and replaces the variables
+func (stat *QStat) buildSQL() (string, error) {
+	_sql, err := stat.composeSQL()
+	if err != nil {
+		return "", err
+	}
+
+	return rebind(stat.sqlStruct.getDialect(), stat.replaceVariables(_sql)), nil
+}
+
+// replaceVariables replaces the variables, e.g. `$T0`, with the quoted names
+func (stat *QStat) replaceVariables(_sql string) string {
+	dialect := stat.sqlStruct.getDialect()
+	for _replace, _new := range stat.Variables {
+		_sql = strings.ReplaceAll(_sql, dialect.Quote(_replace), _replace)
+		_sql = strings.ReplaceAll(_sql, _replace, dialect.Quote(_new))
+	}
+
+	return _sql
+}
+
+// scanDest returns the destinations of rows.Scan() and the raw values they point to
+func (stat *QStat) scanDest() (tmpDS []any, values []sql.RawBytes) {
+	nField := len(stat.sqlStruct.Fields)
+	tmpDS = make([]any, nField)
+	values = make([]sql.RawBytes, nField)
+	for i := 0; i < nField; i++ {
+		tmpDS[i] = &values[i]
+	}
+
+	return
+}
+
+// queryRows runs the SELECT statement with the prepared statement if enabled
+func (stat *QStat) queryRows(_sql string) (*sql.Rows, error) {
+	if stat.preparedStmt {
+		preparedStmt, err := stat.sqlPrepare(_sql)
+		if err != nil {
+			return nil, err
+		}
+		// defer preparedStmt.Close()
+
+		return preparedStmt.QueryContext(stat.Context(), stat.sqlStruct.Values...)
+	}
+
+	return stat.sqlQuery(_sql, stat.sqlStruct.Values...)
+}
+
+func (stat *QStat) composeSQL() (string, error) {
 	if stat.sqlStruct.Length == 0 && !stat.sqlStruct.freeLength {
-		panic(errors.New("dataq: table name is required"))
+		return "", ErrNoTable
 	}
 	var (
 		sql strings.Builder
 	)
 
 	switch stat.Method {
-	case sqlInsert:
-		sql.WriteString(stat.sqlStruct.composeInsertSQL())
-	case sqlBatchInsert:
-		sql.WriteString(stat.sqlStruct.composeBatchInsertSQL())
-	case sqlSelect:
-		sql.WriteString(stat.sqlStruct.composeSelectSQL(stat.Filters))
-
-		if stat.GroupS != "" {
-			sql.WriteString(fmt.Sprintf(" %v", stat.GroupS))
+	case sqlInsert, sqlBatchInsert, sqlUpdate, sqlBatchUpdate, sqlDelete, sqlRestore, sqlCreateTable:
+		if stat.sqlStruct.Table == "" {
+			return "", ErrNoTable
 		}
+	}
+	stat.sqlStruct.Values = make([]any, 0)
+	stat.sqlStruct.trashed = stat.trashed
+	_, softDelete := stat.sqlStruct.softDeleteField()
 
-		if stat.HavingS != "" {
-			sql.WriteString(fmt.Sprintf(" HAVING %v", stat.HavingS))
+	switch stat.Method {
+	case sqlInsert:
+		insert, err := stat.sqlStruct.composeInsertSQL()
+		if err != nil {
+			return "", err
 		}
+		sql.WriteString(insert)
+	case sqlBatchInsert:
+		insert, err := stat.sqlStruct.composeBatchInsertSQL()
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(insert)
+	case sqlSelect:
+		sql.WriteString(stat.composeUnionSQL())
 
 		if stat.OrderS != "" {
 			sql.WriteString(fmt.Sprintf(" ORDER BY %v", stat.OrderS))
@@ -642,9 +672,14 @@ func (stat *QStat) composeSQL() string {
 		}
 
 		if stat.LockFor != "" {
-			sql.WriteString(fmt.Sprintf(" FOR %s", stat.LockFor))
+			sql.WriteString(stat.sqlStruct.getDialect().LockFor(stat.LockFor))
 		}
 	case sqlCount:
+		if len(stat.unions) != 0 {
+			// count the rows of the whole union
+			sql.WriteString(fmt.Sprintf("SELECT COUNT(1) FROM (%s) AS c", strings.TrimSpace(stat.composeUnionSQL())))
+			break
+		}
 		sql.WriteString(stat.sqlStruct.composeCountSQL(stat.Filters))
 		hasGroupBy := false
 		if stat.GroupS != "" {
@@ -671,16 +706,38 @@ func (stat *QStat) composeSQL() string {
 			sql = sqltemp
 		}
 	case sqlUpdate:
-		sql.WriteString(stat.sqlStruct.composeUpdateSQL(stat.Filters, stat.RowLimit))
+		update, err := stat.sqlStruct.composeUpdateSQL(stat.Filters, stat.RowLimit)
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(update)
 	case sqlBatchUpdate:
-		sql.WriteString(stat.sqlStruct.composeBatchUpdateSQL())
+		update, err := stat.sqlStruct.composeBatchUpdateSQL()
+		if err != nil {
+			return "", err
+		}
+		sql.WriteString(update)
 	case sqlDelete:
-		sql.WriteString(stat.sqlStruct.composeDeleteSQL(stat.Filters))
+		if softDelete && !stat.unscoped {
+			sql.WriteString(stat.sqlStruct.composeSoftDeleteSQL(stat.Filters, valueToSQL(reflect.ValueOf(stat.dbc.now()))))
+		} else {
+			sql.WriteString(stat.sqlStruct.composeDeleteSQL(stat.Filters))
+		}
+	case sqlRestore:
+		if !softDelete {
+			return "", ErrNoSoftDelete
+		}
+		sql.WriteString(stat.sqlStruct.composeRestoreSQL(stat.Filters))
 	case sqlCreateTable:
 		sql.WriteString(stat.sqlStruct.composeCreateTableSQL())
 	}
 
-	return sql.String()
+	return sql.String(), nil
+}
+
+// ExecContext executes the query with ctx
+func (stat *QStat) ExecContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).Exec()
 }
 
 // Insert return *QResult
@@ -705,6 +762,8 @@ func (stat *QStat) Count() *QResult {
 }
 
 // Update return *QResult
+// The single row update keeps the Where() filters and Limit() of the statement, also the ones set for Query,
+// call ClearWhere().Limit(0) to update by the primary key only
 func (stat *QStat) Update() *QResult {
 	stat.Method = sqlUpdate
 
@@ -743,11 +802,51 @@ func (stat *QStat) CreateTable() *QResult {
 	return stat.Exec()
 }
 
+// InsertContext is Insert with ctx
+func (stat *QStat) InsertContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).Insert()
+}
+
+// QueryContext is Query with ctx
+func (stat *QStat) QueryContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).Query()
+}
+
+// CountContext is Count with ctx
+func (stat *QStat) CountContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).Count()
+}
+
+// UpdateContext is Update with ctx
+func (stat *QStat) UpdateContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).Update()
+}
+
+// DeleteContext is Delete with ctx
+func (stat *QStat) DeleteContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).Delete()
+}
+
+// BatchInsertContext is BatchInsert with ctx
+func (stat *QStat) BatchInsertContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).BatchInsert()
+}
+
+// BatchUpdateContext is BatchUpdate with ctx
+func (stat *QStat) BatchUpdateContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).BatchUpdate()
+}
+
+// CreateTableContext is CreateTable with ctx
+func (stat *QStat) CreateTableContext(ctx context.Context) *QResult {
+	return stat.WithContext(ctx).CreateTable()
+}
+
 func (stat *QStat) sqlExec(_sql string, args ...any) (rawResult sql.Result, err error) {
 	if stat.dbc.tx != nil {
-		rawResult, err = stat.dbc.tx.Exec(_sql, args...)
+		rawResult, err = stat.dbc.tx.ExecContext(stat.Context(), _sql, args...)
 	} else {
-		rawResult, err = stat.dbc.db.Exec(_sql, args...)
+		rawResult, err = stat.dbc.db.ExecContext(stat.Context(), _sql, args...)
 	}
 
 	return
@@ -755,20 +854,29 @@ func (stat *QStat) sqlExec(_sql string, args ...any) (rawResult sql.Result, err
 
 func (stat *QStat) sqlQuery(_sql string, args ...any) (rawRows *sql.Rows, err error) {
 	if stat.dbc.tx != nil {
-		rawRows, err = stat.dbc.tx.Query(_sql, args...)
+		rawRows, err = stat.dbc.tx.QueryContext(stat.Context(), _sql, args...)
 	} else {
-		rawRows, err = stat.dbc.db.Query(_sql, args...)
+		rawRows, err = stat.dbc.db.QueryContext(stat.Context(), _sql, args...)
 	}
 
 	return
 }
 
+func (stat *QStat) sqlQueryRow(_sql string, args ...any) *sql.Row {
+	if stat.dbc.tx != nil {
+		return stat.dbc.tx.QueryRowContext(stat.Context(), _sql, args...)
+	}
+
+	return stat.dbc.db.QueryRowContext(stat.Context(), _sql, args...)
+}
+
 func (stat *QStat) sqlPrepare(_sql string) (preparedStmt *sql.Stmt, err error) {
+	ctx := stat.Context()
 	if _stmt, ok := stat.dbc.preparedStmt.Load(_sql); ok {
 		stmt := _stmt.(*sql.Stmt)
 
 		if stat.dbc.tx != nil {
-			preparedStmt = stat.dbc.tx.Stmt(stmt)
+			preparedStmt = stat.dbc.tx.StmtContext(ctx, stmt)
 		} else {
 			preparedStmt = stmt
 		}
@@ -781,7 +889,7 @@ func (stat *QStat) sqlPrepare(_sql string) (preparedStmt *sql.Stmt, err error) {
 		stat.dbc.shared.mux.RUnlock()
 
 		if stat.dbc.tx != nil {
-			preparedStmt = stat.dbc.tx.Stmt(stmt)
+			preparedStmt = stat.dbc.tx.StmtContext(ctx, stmt)
 		} else {
 			preparedStmt = stmt
 		}
@@ -791,14 +899,14 @@ func (stat *QStat) sqlPrepare(_sql string) (preparedStmt *sql.Stmt, err error) {
 	stat.dbc.shared.mux.RUnlock()
 
 	if stat.dbc.tx != nil {
-		preparedStmt, err = stat.dbc.tx.Prepare(_sql)
+		preparedStmt, err = stat.dbc.tx.PrepareContext(ctx, _sql)
 
 		stat.dbc.preparedStmt.Store(_sql, preparedStmt)
 
 		return
 	}
 
-	preparedStmt, err = stat.dbc.db.Prepare(_sql)
+	preparedStmt, err = stat.dbc.db.PrepareContext(ctx, _sql)
 	if err == nil {
 		stat.dbc.shared.mux.Lock()
 		stat.dbc.shared.preparedStmt[_sql] = preparedStmt
@@ -811,19 +919,9 @@ func (stat *QStat) sqlPrepare(_sql string) (preparedStmt *sql.Stmt, err error) {
 }
 
 func (stat *QStat) QueryRowUnsafe(query string, args ...any) (row *sql.Row) {
-	if stat.dbc.tx != nil {
-		row = stat.dbc.tx.QueryRow(query, args...)
-	} else {
-		row = stat.dbc.db.QueryRow(query, args...)
-	}
-
-	return
+	return stat.dbc.QueryRowContextUnsafe(stat.Context(), query, args...)
 }
 
 func (stat *QStat) ExecUnsafe(query string, args ...any) (sql.Result, error) {
-	if stat.dbc.tx != nil {
-		return stat.dbc.tx.Exec(query, args...)
-	} else {
-		return stat.dbc.db.Exec(query, args...)
-	}
+	return stat.dbc.ExecContextUnsafe(stat.Context(), query, args...)
 }
@@ -0,0 +1,37 @@
+package dataq
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestContextCanceled(t *testing.T) {
+	var queries []string
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		queries = append(queries, query)
+		return fakeResponse{AffectedRows: 1}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		exec func() error
+	}{
+		{"query", func() error { return db.Model(&batchPerson{ID: 1}).QueryContext(ctx).Error }},
+		{"exec", func() error { return db.Model(&batchPerson{ID: 1, Name: "Mike"}).UpdateContext(ctx).Error }},
+		{"bound statement", func() error { return db.Model(&batchPerson{ID: 1}).WithContext(ctx).Delete().Error }},
+		{"bound connection", func() error { return db.WithContext(ctx).Model(&batchPerson{ID: 1}).Query().Error }},
+		{"begin", func() error { return db.BeginContext(ctx).Err() }},
+	}
+	for _, tt := range tests {
+		if err := tt.exec(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: got %v, want context.Canceled", tt.name, err)
+		}
+	}
+	if len(queries) != 0 {
+		t.Fatalf("got %q, want no statement", queries)
+	}
+}
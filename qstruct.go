@@ -1,8 +1,8 @@
 package dataq
 
 import (
+	"database/sql/driver"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"reflect"
 	"sort"
@@ -12,6 +12,7 @@ import (
 )
 
 type qStruct struct {
+	dialect               Dialect
 	Table                 string
 	TableAlias            string
 	CountOn               string
@@ -19,6 +20,10 @@ type qStruct struct {
 	Index                 []qField
 	Fields                []qField
 	WithStat              string
+	WithValues            []any
+	FromSub               string
+	FromValues            []any
+	JoinValues            []any
 	Joins                 []string
 	Wheres                []string
 	Sets                  []qClause
@@ -27,9 +32,13 @@ type qStruct struct {
 	QueryOnly             bool
 	BatchValue            []map[string]any
 	Schema                []string
+	Relations             []qRelation
 	OnDuplicateKeyUpdate  bool
 	DuplicateKeyUpdateCol map[string]any
 	freeLength            bool
+	trashed               qTrashed
+	// snapshot of the queried rows, see takeSnapshot
+	snapshot [][]any
 }
 
 type qClause struct {
@@ -44,6 +53,7 @@ type columnMapPkValues map[string]map[any]*columnValue
 
 type columnValue struct {
 	Stmt []string
+	Keys []string
 	Pk   any
 	Val  []any
 	Type string
@@ -69,6 +79,20 @@ func (_s *qStruct) IsBatchValueEmpty() bool {
 	return len(_s.BatchValue) == 0
 }
 
+// getDialect returns the dialect of the statement, MySQL by default
+func (_s *qStruct) getDialect() Dialect {
+	if _s.dialect == nil {
+		return MySQL
+	}
+
+	return _s.dialect
+}
+
+// quote quotes the identifier with the dialect
+func (_s *qStruct) quote(ident string) string {
+	return _s.getDialect().Quote(ident)
+}
+
 func (_s *qStruct) hasIndex() bool {
 	return len(_s.Index) != 0
 }
@@ -93,70 +117,54 @@ func (_s *qStruct) getRowValue(idxSlice int) (ret reflect.Value) {
 	return
 }
 
-func (_s *qStruct) getValueInterface(idxField, idxArray int) (ret any) {
-	var typeName reflect.Type
-	var thisValue reflect.Value
+func (_s *qStruct) getValueInterface(idxField []int, idxArray int) (ret any) {
 	if _s.Value.Kind() != reflect.Slice {
-		typeName = _s.Value.Field(idxField).Type()
-		thisValue = _s.Value.Field(idxField)
-		ret = _s.Value.Field(idxField).Interface()
-	} else {
-		typeName = _s.Value.Index(idxArray).Field(idxField).Type()
-		thisValue = _s.Value.Index(idxArray).Field(idxField)
-		ret = _s.Value.Index(idxArray).Field(idxField).Interface()
+		return valueToSQL(_s.Value.FieldByIndex(idxField))
 	}
 
-	// NOTE: uint output 0x00
-	switch typeName.Name() {
-	case "Time":
-		return ret.(time.Time).Format(ConfigMySQLDateTimeFormat)
-	case "QBool":
-		_val := ret.(QBool)
-		if _val.Valid {
-			return _val.Value
-		}
+	return valueToSQL(_s.Value.Index(idxArray).FieldByIndex(idxField))
+}
 
+// valueToSQL converts the value of a field to the value to be bound
+func valueToSQL(thisValue reflect.Value) (ret any) {
+	if !thisValue.IsValid() {
 		return nil
-	case "QInt":
-		_val := ret.(QInt)
-		if _val.Valid {
-			return _val.Value
+	}
+	// nil <-> NULL
+	if thisValue.Kind() == reflect.Ptr {
+		if thisValue.IsNil() {
+			return nil
 		}
 
-		return nil
-	case "QFloat64":
-		_val := ret.(QFloat64)
-		if _val.Valid {
-			return _val.Value
-		}
+		return valueToSQL(thisValue.Elem())
+	}
 
-		return nil
-	case "QString":
-		_val := ret.(QString)
-		if _val.Valid {
-			return _val.Value
+	typeName := thisValue.Type()
+	ret = thisValue.Interface()
+
+	if valuer, ok := asValuer(thisValue); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			// the driver will call Value() again and report the error
+			return valuer
 		}
 
-		return nil
-	case "QStrings":
-		_val := ret.(QStrings)
-		if _val.Valid {
-			if len(_val.Value) < 1 {
-				return "[]"
-			}
+		return val
+	}
+	if valuer, ok := ret.(qValuer); ok {
+		val, _ := valuer.qValue()
 
-			d, _ := json.Marshal(_val.Value)
-			return d
-		}
+		return val
+	}
 
-		return nil
-	case "QTime":
-		_val := ret.(QTime)
-		if _val.Valid {
-			return _val.Value.Format(ConfigMySQLDateTimeFormat)
+	// NOTE: uint output 0x00
+	switch typeName.Name() {
+	case "Time":
+		if _val, ok := ret.(time.Time); ok {
+			return _val.Format(ConfigMySQLDateTimeFormat)
 		}
 
-		return nil
+		return ret
 	default:
 		switch typeName.Kind() {
 		case reflect.Map:
@@ -180,16 +188,34 @@ func (_s *qStruct) getValueInterface(idxField, idxArray int) (ret any) {
 	}
 }
 
-func (_s *qStruct) isValueEmpty(idxField, idxArray int) bool {
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// asValuer returns the driver.Valuer of the value, the pointer receiver is also accepted
+func asValuer(thisValue reflect.Value) (driver.Valuer, bool) {
+	if thisValue.Type().Implements(valuerType) {
+		if thisValue.Kind() == reflect.Ptr && thisValue.IsNil() {
+			return nil, false
+		}
+
+		return thisValue.Interface().(driver.Valuer), true
+	}
+	if thisValue.CanAddr() && thisValue.Addr().Type().Implements(valuerType) {
+		return thisValue.Addr().Interface().(driver.Valuer), true
+	}
+
+	return nil, false
+}
+
+func (_s *qStruct) isValueEmpty(idxField []int, idxArray int) bool {
 	return _s.getValueInterface(idxField, idxArray) == _s.getValueEmptyValue(idxField, idxArray)
 }
 
-func (_s *qStruct) getValueEmptyValue(idxField, idxArray int) any {
+func (_s *qStruct) getValueEmptyValue(idxField []int, idxArray int) any {
 	var typeName reflect.Type
 	if _s.Value.Kind() != reflect.Slice {
-		typeName = _s.Value.Field(idxField).Type()
+		typeName = _s.Value.FieldByIndex(idxField).Type()
 	} else {
-		typeName = _s.Value.Index(idxArray).Field(idxField).Type()
+		typeName = _s.Value.Index(idxArray).FieldByIndex(idxField).Type()
 	}
 
 	switch typeName.Name() {
@@ -207,6 +233,8 @@ func (_s *qStruct) getValueEmptyValue(idxField, idxArray int) any {
 		return time.Now().UTC().Format(ConfigMySQLDateTimeFormat)
 	default:
 		switch typeName.Kind() {
+		case reflect.Ptr:
+			return nil
 		case reflect.Map:
 			return "{}"
 		case reflect.Slice:
@@ -224,7 +252,7 @@ func (_s *qStruct) getIndexSQL() (sql string) {
 				where.WriteString(" AND ")
 			}
 
-			where.WriteString(fmt.Sprintf("`%s`.`%s` IN ", _index.Table, _index.ColName))
+			where.WriteString(fmt.Sprintf("%s.%s IN ", _s.quote(_index.Table), _s.quote(_index.ColName)))
 			var in = make([]string, _s.Length)
 			for i := 0; i < _s.Length; i++ {
 				in[i] = "?" // fmt.Sprintf("%#v", _s.getValueInterface(_index.ValIdx, i))
@@ -255,41 +283,43 @@ func (_s *qStruct) setFieldIgnoreNull(i int) *qStruct {
 }
 
 // If qStruct.Length > 1, make sure each element has the same columns to insert!
-func (_s *qStruct) composeInsertSQL() string {
+func (_s *qStruct) composeInsertSQL() (string, error) {
 	var (
-		sql    strings.Builder
-		colVal = make(map[string]*columnValue)
-		key    string
-		col    []string
-		val    []string
-		cV     *columnValue
+		sql     strings.Builder
+		dialect = _s.getDialect()
+		colVal  = make(map[string]*columnValue)
+		key     string
+		col     []string
+		val     []string
+		cV      *columnValue
 	)
 	_s.Values = make([]any, 0, _s.Length*len(_s.Fields))
 	for i := 0; i < _s.Length; i++ {
 		for _, _field := range _s.Fields {
 			// ignore `TABLE`. prefix
 			if !isEqual(_s.getValueInterface(_field.ValIdx, i), _field.AsNull) || _field.Init {
-				key = fmt.Sprintf("`%s`", _field.ColName)
+				key = _s.quote(_field.ColName)
 				if colVal[key] == nil {
 					cV = _s.AllocColumnValue()
 					cV.Stmt = make([]string, 0)
 					cV.Val = make([]any, 0)
+					if i == 0 {
+						col = append(col, key)
+					}
 				} else {
 					cV = colVal[key]
 				}
 
 				placeholder := "?"
 				if _field.JsonCast {
-					placeholder = "CAST(? AS JSON)"
+					placeholder = dialect.JSONCast("?")
 				}
 
 				if _field.Json != "" {
 					cV.Type = "json"
-
-					cV.Stmt = append(cV.Stmt, fmt.Sprintf("'%s', %s", _field.Json, placeholder))
-				} else {
-					cV.Stmt = append(cV.Stmt, placeholder)
+					cV.Keys = append(cV.Keys, _field.Json)
 				}
+				cV.Stmt = append(cV.Stmt, placeholder)
 				cV.Val = append(cV.Val, _s.getValueInterface(_field.ValIdx, i))
 				colVal[key] = cV
 			}
@@ -297,93 +327,108 @@ func (_s *qStruct) composeInsertSQL() string {
 
 		val = make([]string, 0, len(colVal))
 		if i == 0 {
-			col = make([]string, 0, len(colVal))
-			for _key, _val := range colVal {
-				col = append(col, _key)
-				if _val.Type == "json" {
-					val = append(val, fmt.Sprintf("JSON_OBJECT(%s)", strings.Join(_val.Stmt, ", ")))
-				} else {
-					val = append(val, _val.Stmt[0])
-				}
-				_s.Values = append(_s.Values, _val.Val...)
-				colVal[_key].Free()
-				colVal[_key] = nil
-				delete(colVal, _key)
-			}
-			sql.WriteString(fmt.Sprintf("INSERT INTO `%s` (%s) VALUES", _s.Table, strings.Join(col, ", ")))
+			sql.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES", _s.quote(_s.Table), strings.Join(col, ", ")))
 		} else {
-			for _, _key := range col {
-				if colVal[_key].Type == "json" {
-					val = append(val, fmt.Sprintf("JSON_OBJECT(%s)", strings.Join(colVal[_key].Stmt, ", ")))
-				} else {
-					val = append(val, colVal[_key].Stmt[0])
-				}
-				_s.Values = append(_s.Values, colVal[_key].Val...)
-				colVal[_key].Free()
-				colVal[_key] = nil
-				delete(colVal, _key)
-			}
 			sql.WriteByte(',')
 		}
+		for _, _key := range col {
+			if colVal[_key].Type == "json" {
+				val = append(val, dialect.JSONObject(colVal[_key].Keys, colVal[_key].Stmt))
+			} else {
+				val = append(val, colVal[_key].Stmt[0])
+			}
+			_s.Values = append(_s.Values, colVal[_key].Val...)
+			colVal[_key].Free()
+			colVal[_key] = nil
+			delete(colVal, _key)
+		}
 
 		sql.WriteString(fmt.Sprintf(" (%s)", strings.Join(val, ",")))
 	}
 
 	if _s.OnDuplicateKeyUpdate {
 		val = make([]string, 0, len(_s.DuplicateKeyUpdateCol))
-		for _col, _val := range _s.DuplicateKeyUpdateCol {
-			val = append(val, fmt.Sprintf("%s=%s", _col, _val))
+		for _, _col := range sortedKeys(_s.DuplicateKeyUpdateCol) {
+			val = append(val, fmt.Sprintf("%s=%s", _col, _s.DuplicateKeyUpdateCol[_col]))
+		}
+		upsert, err := dialect.Upsert(_s.indexColumns(), val)
+		if err != nil {
+			return "", err
 		}
-		sql.WriteString(fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s", strings.Join(val, ",")))
+		sql.WriteString(upsert)
 	}
 
-	return sql.String()
+	return sql.String(), nil
+}
+
+// indexColumns returns the column names of the index
+func (_s *qStruct) indexColumns() []string {
+	cols := make([]string, len(_s.Index))
+	for _idx, _index := range _s.Index {
+		cols[_idx] = _index.ColName
+	}
+
+	return cols
+}
+
+// sortedKeys returns the sorted keys of the map
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for _key := range m {
+		keys = append(keys, _key)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
 
-func (_s *qStruct) composeBatchInsertSQL() string {
+func (_s *qStruct) composeBatchInsertSQL() (string, error) {
 	if len(_s.BatchValue) == 0 {
-		return ""
+		return "", nil
 	}
 
 	var (
 		sql  strings.Builder
-		col  string
-		val  string
-		vals string
+		cols []string
+		val  []string
+		vals []string
 		keys []string
 	)
+	_s.Values = make([]any, 0, len(_s.BatchValue)*len(_s.BatchValue[0]))
 
 	for _idx, _values := range _s.BatchValue {
-		val = ""
-
 		if _idx == 0 {
 			for _key := range _values {
 				keys = append(keys, _key)
 			}
 			sort.Strings(keys)
 			for _, _key := range keys {
-				col += fmt.Sprintf(" `%s`,", _key)
+				cols = append(cols, _s.quote(_key))
 			}
 		}
-		for _, _key := range keys {
-			val += fmt.Sprintf(" %#v,", _values[_key])
+		val = make([]string, len(keys))
+		for _i, _key := range keys {
+			val[_i] = "?"
+			_s.Values = append(_s.Values, valueToSQL(reflect.ValueOf(_values[_key])))
 		}
-		val = val[1 : len(val)-1]
-		vals += "(" + val + "), "
+		vals = append(vals, fmt.Sprintf("(%s)", strings.Join(val, ", ")))
 	}
-	sql.WriteString(fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s", _s.Table, col[1:len(col)-1], vals[:len(vals)-2]))
+	sql.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", _s.quote(_s.Table), strings.Join(cols, ", "), strings.Join(vals, ", ")))
 
 	if _s.OnDuplicateKeyUpdate {
-		val = ""
-		for _col, _val := range _s.DuplicateKeyUpdateCol {
-			val += fmt.Sprintf(" `%s` = %s,", _col, _val)
+		val = make([]string, 0, len(_s.DuplicateKeyUpdateCol))
+		for _, _col := range sortedKeys(_s.DuplicateKeyUpdateCol) {
+			val = append(val, fmt.Sprintf("%s = %s", _s.quote(_col), _s.DuplicateKeyUpdateCol[_col]))
+		}
+		upsert, err := _s.getDialect().Upsert(_s.indexColumns(), val)
+		if err != nil {
+			return "", err
 		}
-		val = val[1 : len(val)-1]
-		sql.WriteString(fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s", val))
+		sql.WriteString(upsert)
 	}
 	sql.WriteByte(';')
 
-	return sql.String()
+	return sql.String(), nil
 }
 
 func (_s *qStruct) composeSelectFieldSQL() string {
@@ -392,7 +437,7 @@ func (_s *qStruct) composeSelectFieldSQL() string {
 		fields = make([]string, 0, len(_s.Fields))
 	)
 	for _, _field := range _s.Fields {
-		fields = append(fields, _field.SelectString())
+		fields = append(fields, _field.SelectString(_s.getDialect()))
 	}
 
 	sql.WriteString(strings.Join(fields, ", "))
@@ -408,16 +453,13 @@ func (_s *qStruct) composeSelectSQL(filters []qClause) string {
 	_s.Values = make([]any, 0)
 
 	sql.WriteString(fmt.Sprintf("%s SELECT %s", _s.composeWithStatement(), _s.composeSelectFieldSQL()))
+	_s.Values = append(_s.Values, _s.WithValues...)
 
-	if _s.Table != "" {
-		sql.WriteString(fmt.Sprintf(" FROM `%s`", _s.Table))
-		if _s.TableAlias != "" {
-			sql.WriteString(fmt.Sprintf(" AS `%s`", _s.TableAlias))
-		}
-	}
-
-	if _s.hasJoins() {
+	if _s.Table != "" || _s.FromSub != "" {
+		sql.WriteString(_s.composeFromSQL())
+	} else if _s.hasJoins() {
 		sql.WriteString(fmt.Sprintf(" %s", strings.Join(_s.Joins, " ")))
+		_s.Values = append(_s.Values, _s.JoinValues...)
 	}
 
 	condition := _s.composeWhereIndexCondition(filters)
@@ -428,13 +470,37 @@ func (_s *qStruct) composeSelectSQL(filters []qClause) string {
 	return sql.String()
 }
 
+// composeFromSQL returns the FROM and JOIN clauses, the values of the subqueries are appended in order
+func (_s *qStruct) composeFromSQL() string {
+	var (
+		sql strings.Builder
+	)
+
+	if _s.FromSub != "" {
+		sql.WriteString(fmt.Sprintf(" FROM %s", _s.FromSub))
+		_s.Values = append(_s.Values, _s.FromValues...)
+	} else {
+		sql.WriteString(fmt.Sprintf(" FROM %s", _s.quote(_s.Table)))
+		if _s.TableAlias != "" {
+			sql.WriteString(fmt.Sprintf(" AS %s", _s.quote(_s.TableAlias)))
+		}
+	}
+
+	if _s.hasJoins() {
+		sql.WriteString(fmt.Sprintf(" %s", strings.Join(_s.Joins, " ")))
+		_s.Values = append(_s.Values, _s.JoinValues...)
+	}
+
+	return sql.String()
+}
+
 func (s *qStruct) composeDeleteSQL(filters []qClause) string {
 	var (
 		sql strings.Builder
 	)
-	sql.WriteString(fmt.Sprintf("DELETE FROM `%s`", s.Table))
+	sql.WriteString(fmt.Sprintf("DELETE FROM %s", s.quote(s.Table)))
 
-	condition := s.composeWhereIndexCondition(filters)
+	condition := s.composeWhereCondition(filters, false)
 	if len(condition) > 0 {
 		sql.WriteString(fmt.Sprintf(" WHERE %s", condition))
 	}
@@ -448,18 +514,11 @@ func (_s *qStruct) composeCountSQL(filters []qClause) string {
 	)
 
 	if _s.CountOn == "" {
-		sql.WriteString(fmt.Sprintf("SELECT COUNT(1) FROM `%s`", _s.Table))
+		sql.WriteString("SELECT COUNT(1)")
 	} else {
-		sql.WriteString(fmt.Sprintf("SELECT COUNT(%s) FROM `%s`", _s.CountOn, _s.Table))
-	}
-
-	if _s.TableAlias != "" {
-		sql.WriteString(fmt.Sprintf(" AS `%s`", _s.TableAlias))
-	}
-
-	if _s.hasJoins() {
-		sql.WriteString(fmt.Sprintf(" %s", strings.Join(_s.Joins, " ")))
+		sql.WriteString(fmt.Sprintf("SELECT COUNT(%s)", _s.CountOn))
 	}
+	sql.WriteString(_s.composeFromSQL())
 
 	condition := _s.composeWhereIndexCondition(filters)
 	if condition != "" {
@@ -477,12 +536,19 @@ func (s *qStruct) composeWithStatement() string {
 	return sql.String()
 }
 
+// composeWhereIndexCondition returns the condition of SELECT and COUNT, which declare the `TABLEALIAS`
 func (s *qStruct) composeWhereIndexCondition(filters []qClause) string {
+	return s.composeWhereCondition(filters, true)
+}
+
+// composeWhereCondition returns the condition of the index, `WHERE` tags, trashed scope and filters
+func (s *qStruct) composeWhereCondition(filters []qClause, aliased bool) string {
 	var (
 		condition = make([]string, 0, 10)
 	)
 
-	if s.hasIndex() {
+	// the empty slice model is not filtered by the index
+	if s.hasIndex() && s.Length > 0 {
 		condition = append(condition, fmt.Sprintf("(%s)", s.getIndexSQL()))
 	}
 
@@ -490,6 +556,10 @@ func (s *qStruct) composeWhereIndexCondition(filters []qClause) string {
 		condition = append(condition, fmt.Sprintf("(%s)", strings.Join(s.Wheres, " AND ")))
 	}
 
+	if softDelete := s.softDeleteCondition(aliased); softDelete != "" {
+		condition = append(condition, fmt.Sprintf("(%s)", softDelete))
+	}
+
 	if len(filters) != 0 {
 		var sqlFilter strings.Builder
 		sqlFilter.WriteByte('(')
@@ -516,7 +586,7 @@ func (s *qStruct) composeWhereIndexCondition(filters []qClause) string {
 //				END,
 //	...
 //	WHERE pk IN (1, 2, 3);
-func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
+func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) (string, error) {
 	var (
 		sql       strings.Builder
 		updates   []string
@@ -530,53 +600,36 @@ func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
 	if _s.Length == 1 {
 		var (
 			colVal = make(columnMapValue)
+			cols   []string
 			cV     *columnValue
 		)
-		for _, _field := range _s.Fields {
-			if _field.PassUpdate {
+		for _j, _field := range _s.Fields {
+			if _field.PassUpdate || _field.Version || _field.SoftDelete != softDeleteNone {
 				continue
 			}
 			if !_field.IsIndex && _field.Table == _s.Table {
-				key = fmt.Sprintf("`%s`", _field.ColName)
+				key = _s.quote(_field.ColName)
 				if colVal[key] == nil {
 					cV = _s.AllocColumnValue()
 					cV.Stmt = make([]string, 0)
+					cols = append(cols, key)
 				} else {
 					cV = colVal[key]
 				}
 
 				if _field.Self != "" {
 					cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s%s", key, _field.Self))
-				} else if !isEqual(_s.getValueInterface(_field.ValIdx, 0), _field.AsNull) || _field.IgnoreNull || (_field.AsClear != nil && _field.AsClear == _s.getValueInterface(_field.ValIdx, 0)) {
-					placeholder := "?"
-					if _field.JsonCast {
-						placeholder = "CAST(? AS JSON)"
-					}
+				} else if changed, tracked := _s.isChanged(0, _j); changed || _field.IgnoreNull || (_field.AsClear != nil && _field.AsClear == _s.getValueInterface(_field.ValIdx, 0)) ||
+					!tracked && !isEqual(_s.getValueInterface(_field.ValIdx, 0), _field.AsNull) {
 					if _field.Json != "" {
 						cV.Type = "json"
-
-						if _field.JsonMerge != "" {
-							cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s', JSON_MERGE(%s, %s)", _field.Json, _field.JsonMerge, placeholder))
-						} else if _field.JsonMergePreserve != "" {
-							cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s', JSON_MERGE_PRESERVE(%s, %s)", _field.Json, _field.JsonMergePreserve, placeholder))
-						} else if _field.JsonMergePatch != "" {
-							cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s', JSON_MERGE_PATCH(%s, %s)", _field.Json, _field.JsonMergePatch, placeholder))
-						} else if _field.JsonArrayAppend != "" {
-							cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s', JSON_ARRAY_APPEND(%s, %s)", _field.Json, _field.JsonArrayAppend, placeholder))
-						} else {
-							cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s', %s", _field.Json, placeholder))
-						}
-					} else if _field.JsonMerge != "" {
-						cV.Stmt = append(cV.Stmt, fmt.Sprintf("JSON_MERGE(%s, %s)", _field.JsonMerge, placeholder))
-					} else if _field.JsonMergePreserve != "" {
-						cV.Stmt = append(cV.Stmt, fmt.Sprintf("JSON_MERGE_PRESERVE(%s, %s)", _field.JsonMergePreserve, placeholder))
-					} else if _field.JsonMergePatch != "" {
-						cV.Stmt = append(cV.Stmt, fmt.Sprintf("JSON_MERGE_PATCH(%s, %s)", _field.JsonMergePatch, placeholder))
-					} else if _field.JsonArrayAppend != "" {
-						cV.Stmt = append(cV.Stmt, fmt.Sprintf("JSON_ARRAY_APPEND(%s, %s)", _field.JsonArrayAppend, placeholder))
-					} else {
-						cV.Stmt = append(cV.Stmt, placeholder)
+						cV.Keys = append(cV.Keys, _field.Json)
+					}
+					expr, err := _s.updateExpr(_field)
+					if err != nil {
+						return "", err
 					}
+					cV.Stmt = append(cV.Stmt, expr)
 
 					if _field.AsClear != nil && _field.AsClear == _s.getValueInterface(_field.ValIdx, 0) {
 						cV.Val = append(cV.Val, _s.getValueEmptyValue(_field.ValIdx, 0))
@@ -590,17 +643,21 @@ func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
 
 		updates = make([]string, 0, len(colVal))
 
-		for _key, _val := range colVal {
+		for _, _key := range cols {
+			_val := colVal[_key]
 			update.Reset()
 			if len(_val.Stmt) > 0 {
 				update.WriteString(fmt.Sprintf("%s=", _key))
 				if _val.Type == "json" {
-					update.WriteString(fmt.Sprintf("IF(JSON_VALID(%s), JSON_SET(%s, '$.%s), JSON_OBJECT('%s))", _key, _key, strings.Join(_val.Stmt, ", '$."), strings.Join(_val.Stmt, ", '")))
-					_s.Values = append(_s.Values, _val.Val...)
+					_set, _repeat := _s.getDialect().JSONSet(_key, _val.Keys, _val.Stmt)
+					update.WriteString(_set)
+					for n := 0; n < _repeat; n++ {
+						_s.Values = append(_s.Values, _val.Val...)
+					}
 				} else {
 					update.WriteString(_val.Stmt[0])
+					_s.Values = append(_s.Values, _val.Val...)
 				}
-				_s.Values = append(_s.Values, _val.Val...)
 				updates = append(updates, update.String())
 			}
 			colVal[_key].Free()
@@ -615,15 +672,31 @@ func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
 			}
 		}
 
+		// nothing to update
+		if len(updates) == 0 {
+			_s.Values = make([]any, 0)
+			return "", nil
+		}
+
+		_version, hasVersion := _s.versionField()
+		if hasVersion {
+			updates = append(updates, _s.composeVersionSet(_version))
+		}
+
 		if _s.hasIndex() {
 			indexes := make([]string, 0)
 			for _, _index := range _s.Index {
-				indexes = append(indexes, fmt.Sprintf("`%s`=?", _index.ColName))
+				indexes = append(indexes, fmt.Sprintf("%s=?", _s.quote(_index.ColName)))
 				_s.Values = append(_s.Values, _s.getValueInterface(_index.ValIdx, 0))
 			}
 			condition = append(condition, fmt.Sprintf("(%s)", strings.Join(indexes, " AND ")))
 		}
 
+		if hasVersion {
+			condition = append(condition, fmt.Sprintf("(%s=?)", _s.quote(_version.ColName)))
+			_s.Values = append(_s.Values, _s.getValueInterface(_version.ValIdx, 0))
+		}
+
 		if _s.hasWheres() {
 			condition = append(condition, fmt.Sprintf("(%s)", strings.Join(_s.Wheres, " AND ")))
 		}
@@ -642,37 +715,46 @@ func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
 			condition = append(condition, sqlFilter.String())
 		}
 
-		if len(condition) > 0 {
-			sql.WriteString(fmt.Sprintf("UPDATE `%s` SET %s WHERE %s", _s.Table, strings.Join(updates, ", "), strings.Join(condition, " AND ")))
-			if hasLimit {
-				sql.WriteString(fmt.Sprintf(" LIMIT %#v", limit))
+		var limitClause string
+		if hasLimit {
+			if limitClause = _s.getDialect().UpdateLimit(limit); limitClause == "" {
+				return "", fmt.Errorf("%w: UPDATE ... LIMIT of %s", ErrNotSupported, _s.getDialect().Name())
 			}
+		}
+		if len(condition) > 0 {
+			sql.WriteString(fmt.Sprintf("UPDATE %s SET %s WHERE %s%s", _s.quote(_s.Table), strings.Join(updates, ", "), strings.Join(condition, " AND "), limitClause))
 		} else if hasLimit {
-			sql.WriteString(fmt.Sprintf("UPDATE `%s` SET%s LIMIT %#v", _s.Table, strings.Join(updates, ", "), limit))
+			sql.WriteString(fmt.Sprintf("UPDATE %s SET %s%s", _s.quote(_s.Table), strings.Join(updates, ", "), limitClause))
 		}
 	} else {
 		// primary key is required!
 		if !_s.hasIndex() {
-			panic(errors.New("dataq: multiple row update must have to specify primary key"))
+			return "", fmt.Errorf("%w: multiple row update must have to specify primary key", ErrNoPrimaryKey)
 		}
-		// the first index as primary key
+		// the first index as primary key,
+		// the rows of the growable slice filled by Query are counted by rowCount
 		var (
-			_PK    = fmt.Sprintf("`%s`", _s.Index[0].ColName)
+			_PK    = _s.quote(_s.Index[0].ColName)
+			n      = _s.rowCount()
 			ids    = make([]string, 0)
+			pks    = make([]any, 0, n)
+			cols   []string
 			colVal = make(columnMapPkValues)
 			cV     *columnValue
 		)
-		for i := 0; i < _s.Length; i++ {
+		for i := 0; i < n; i++ {
 			var _PkVal = _s.getValueInterface(_s.Index[0].ValIdx, i)
-			ids = append(ids, fmt.Sprintf("%#v", _PkVal))
-			for _, _field := range _s.Fields {
-				if _field.PassUpdate {
+			ids = append(ids, "?")
+			pks = append(pks, _PkVal)
+			for _j, _field := range _s.Fields {
+				if _field.PassUpdate || _field.Version || _field.SoftDelete != softDeleteNone {
 					continue
 				}
 				if !_field.IsIndex && _field.Table == _s.Table {
-					key = fmt.Sprintf("`%s`", _field.ColName)
+					key = _s.quote(_field.ColName)
 					if colVal[key] == nil {
 						colVal[key] = make(map[any]*columnValue)
+						cols = append(cols, key)
 					}
 					if colVal[key][_PkVal] == nil {
 						cV = _s.AllocColumnValue()
@@ -682,21 +764,15 @@ func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
 					}
 
 					cV.Pk = _PkVal
-					if !isEqual(_s.getValueInterface(_field.ValIdx, i), _field.AsNull) {
+					if changed, tracked := _s.isChanged(i, _j); changed || !tracked && !isEqual(_s.getValueInterface(_field.ValIdx, i), _field.AsNull) {
 						if _field.Json != "" {
-							placeholder := "?"
-							if _field.JsonCast {
-								placeholder = "CAST(? AS JSON)"
-							}
-
 							cV.Type = "json"
-							if _field.JsonMerge != "" {
-								cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s', JSON_MERGE(%s, %s)", _field.Json, _field.JsonMerge, placeholder))
-							} else if _field.JsonMergePreserve != "" {
-								cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s', JSON_MERGE_PRESERVE(%s, %s)", _field.Json, _field.JsonMergePreserve, placeholder))
-							} else {
-								cV.Stmt = append(cV.Stmt, fmt.Sprintf("%s', %s", _field.Json, placeholder))
+							cV.Keys = append(cV.Keys, _field.Json)
+							expr, err := _s.updateExpr(_field)
+							if err != nil {
+								return "", err
 							}
+							cV.Stmt = append(cV.Stmt, expr)
 						} else {
 							cV.Stmt = append(cV.Stmt, "?")
 						}
@@ -709,32 +785,38 @@ func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
 			}
 		}
 
-		sql.WriteString(fmt.Sprintf("UPDATE `%s` SET ", _s.Table))
+		sql.WriteString(fmt.Sprintf("UPDATE %s SET ", _s.quote(_s.Table)))
 		updates = make([]string, 0, len(colVal))
 		var hasStmt = false
 
-		for _column, _pks := range colVal {
+		for _, _column := range cols {
+			_pks := colVal[_column]
 			update.Reset()
 			hasStmt = false
-			if len(_pks) > 0 {
-				update.WriteString(fmt.Sprintf("%s=CASE %s", _column, _PK))
-				for _pk, _val := range _pks {
-					if len(_val.Stmt) > 0 {
-						hasStmt = true
-						update.WriteString(" WHEN ? THEN ")
-						_s.Values = append(_s.Values, _pk)
-						if _val.Type == "json" {
-							update.WriteString(fmt.Sprintf("IF(JSON_VALID(%s), JSON_SET(%s, '$.%s), JSON_OBJECT('%s))", _column, _column, strings.Join(_val.Stmt, ", '$."), strings.Join(_val.Stmt, ",'")))
+			update.WriteString(fmt.Sprintf("%s=CASE %s", _column, _PK))
+			for _, _pk := range pks {
+				_val := _pks[_pk]
+				if _val == nil {
+					continue
+				}
+				if len(_val.Stmt) > 0 {
+					hasStmt = true
+					update.WriteString(" WHEN ? THEN ")
+					_s.Values = append(_s.Values, _pk)
+					if _val.Type == "json" {
+						_set, _repeat := _s.getDialect().JSONSet(_column, _val.Keys, _val.Stmt)
+						update.WriteString(_set)
+						for n := 0; n < _repeat; n++ {
 							_s.Values = append(_s.Values, _val.Val...)
-						} else {
-							update.WriteString(_val.Stmt[0])
 						}
+					} else {
+						update.WriteString(_val.Stmt[0])
 						_s.Values = append(_s.Values, _val.Val...)
 					}
-					_pks[_pk].Free()
-					_pks[_pk] = nil
-					delete(_pks, _pk)
 				}
+				_pks[_pk].Free()
+				_pks[_pk] = nil
+				delete(_pks, _pk)
 			}
 			if hasStmt {
 				update.WriteString(fmt.Sprintf(" ELSE %s END", _column))
@@ -742,10 +824,59 @@ func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
 			}
 			delete(colVal, _column)
 		}
-		sql.WriteString(fmt.Sprintf("%s WHERE %s IN (%s)", strings.Join(updates, ","), _PK, strings.Join(ids, ",")))
+		// nothing to update
+		if len(updates) == 0 {
+			_s.Values = make([]any, 0)
+			return "", nil
+		}
+
+		if _version, hasVersion := _s.versionField(); hasVersion {
+			// WHERE (pk=? AND version=?) OR ...
+			updates = append(updates, _s.composeVersionSet(_version))
+			for i, _pk := range pks {
+				ids[i] = fmt.Sprintf("(%s=? AND %s=?)", _PK, _s.quote(_version.ColName))
+				_s.Values = append(_s.Values, _pk, _s.getValueInterface(_version.ValIdx, i))
+			}
+			sql.WriteString(fmt.Sprintf("%s WHERE %s", strings.Join(updates, ","), strings.Join(ids, " OR ")))
+		} else {
+			_s.Values = append(_s.Values, pks...)
+			sql.WriteString(fmt.Sprintf("%s WHERE %s IN (%s)", strings.Join(updates, ","), _PK, strings.Join(ids, ",")))
+		}
 	}
 
-	return sql.String()
+	return sql.String(), nil
+}
+
+// updateExpr returns the placeholder of the field wrapped by its JSON function,
+// ErrNotSupported is returned if the dialect has no such function
+func (_s *qStruct) updateExpr(_field qField) (string, error) {
+	var (
+		dialect     = _s.getDialect()
+		placeholder = "?"
+		expr        string
+		function    string
+	)
+	if _field.JsonCast {
+		placeholder = dialect.JSONCast(placeholder)
+	}
+
+	switch {
+	case _field.JsonMerge != "":
+		expr, function = dialect.JSONMergePreserve(_field.JsonMerge, placeholder), "JSONMERGE"
+	case _field.JsonMergePreserve != "":
+		expr, function = dialect.JSONMergePreserve(_field.JsonMergePreserve, placeholder), "JSONMERGEPRESERVE"
+	case _field.JsonMergePatch != "":
+		return dialect.JSONMergePatch(_field.JsonMergePatch, placeholder), nil
+	case _field.JsonArrayAppend != "":
+		expr, function = dialect.JSONArrayAppend(_field.JsonArrayAppend, placeholder), "JSONARRAYAPPEND"
+	default:
+		return placeholder, nil
+	}
+	if expr == "" {
+		return "", fmt.Errorf("%w: %s of %s", ErrNotSupported, function, dialect.Name())
+	}
+
+	return expr, nil
 }
 
 // UPDATE categories
@@ -761,70 +892,69 @@ func (_s *qStruct) composeUpdateSQL(filters []qClause, limit int) string {
 //	WHEN 3 THEN 'New Title 3'
 //	END
 //	WHERE id IN (1,2,3)
-func (_s *qStruct) composeBatchUpdateSQL() string {
+func (_s *qStruct) composeBatchUpdateSQL() (string, error) {
 	if len(_s.BatchValue) == 0 {
-		return ""
+		return "", nil
+	}
+	if !_s.hasIndex() {
+		return "", fmt.Errorf("%w: batch update must have to specify primary key", ErrNoPrimaryKey)
 	}
 
 	var (
-		sql           strings.Builder
-		lenBatchValue = len(_s.BatchValue) - 1
-		lenBatchField = len(_s.BatchValue[0]) - 1
-		fieldName     = make([]string, lenBatchField)
-		indexName     = _s.Index[0].ColName
-		update        string
-		_cond         string
-		condMap       = make(map[string]bool)
-		condition     string
+		sql       strings.Builder
+		indexName = _s.Index[0].ColName
+		cols      []string
+		updates   []string
+		update    strings.Builder
+		condMap   = make(map[string]bool)
+		condition []string
+		condVals  []any
 	)
+	_s.Values = make([]any, 0)
 
-	for _idx, _values := range _s.BatchValue {
-		_i := 0
-		for _col, _val := range _values {
-			if _col != "INDEX" {
-				if _idx == 0 {
-					fieldName[_i] = fmt.Sprintf("`%s` = CASE `%s` ", _col, indexName)
-				}
-				fieldName[_i] += fmt.Sprintf("WHEN %#v THEN %#v ", _values["INDEX"], _val)
-				_cond = fmt.Sprintf("%#v", _values["INDEX"])
-				if !condMap[_cond] {
-					condMap[_cond] = true
-				}
-				if _idx == lenBatchValue {
-					fieldName[_i] += "END"
-				}
-				_i++
-			}
+	for _col := range _s.BatchValue[0] {
+		if _col != "INDEX" {
+			cols = append(cols, _col)
+		}
+	}
+	sort.Strings(cols)
+
+	for _, _values := range _s.BatchValue {
+		_cond := fmt.Sprintf("%#v", _values["INDEX"])
+		if !condMap[_cond] {
+			condMap[_cond] = true
+			condition = append(condition, "?")
+			condVals = append(condVals, valueToSQL(reflect.ValueOf(_values["INDEX"])))
 		}
 	}
 
-	update = strings.Join(fieldName, ", ")
-
-	for _condition := range condMap {
-		condition += _condition + ", "
+	for _, _col := range cols {
+		update.Reset()
+		update.WriteString(fmt.Sprintf("%s = CASE %s", _s.quote(_col), _s.quote(indexName)))
+		for _, _values := range _s.BatchValue {
+			if _val, ok := _values[_col]; ok {
+				update.WriteString(" WHEN ? THEN ?")
+				_s.Values = append(_s.Values, valueToSQL(reflect.ValueOf(_values["INDEX"])), valueToSQL(reflect.ValueOf(_val)))
+			}
+		}
+		update.WriteString(fmt.Sprintf(" ELSE %s END", _s.quote(_col)))
+		updates = append(updates, update.String())
 	}
+	_s.Values = append(_s.Values, condVals...)
 
-	sql.WriteString(fmt.Sprintf("UPDATE `%s` SET %s WHERE `%s` IN (%s);", _s.Table, update, indexName, condition[:len(condition)-2]))
+	sql.WriteString(fmt.Sprintf("UPDATE %s SET %s WHERE %s IN (%s);", _s.quote(_s.Table), strings.Join(updates, ", "), _s.quote(indexName), strings.Join(condition, ", ")))
 
-	return sql.String()
+	return sql.String(), nil
 }
 
 func (_s *qStruct) composeCreateTableSQL() string {
-	var (
-		sql    strings.Builder
-		fields = make([]string, len(_s.Fields))
-	)
-
-	for _idx, _values := range _s.Fields {
-		fields[_idx] = fmt.Sprintf("`%s` %s", _values.ColName, _values.Schema)
+	defs := make([]string, 0, len(_s.Fields)+len(_s.Schema))
+	for _, _values := range _s.Fields {
+		defs = append(defs, fmt.Sprintf("%s %s", _s.quote(_values.ColName), _values.Schema))
 	}
+	defs = append(defs, _s.Schema...)
 
-	fieldsDef := strings.Join(fields, ", ")
-	indexDef := strings.Join(_s.Schema, ", ")
-
-	sql.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` (%s%s) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;", _s.Table, fieldsDef, indexDef))
-
-	return sql.String()
+	return _s.getDialect().CreateTable(_s.Table, defs)
 }
 
 func (s *qStruct) GetValues() []any {
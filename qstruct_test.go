@@ -0,0 +1,176 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type batchPerson struct {
+	ID   int64  `COL:"ID" TABLE:"Person" INDEX:""`
+	Name string `COL:"NAME"`
+	Age  int    `COL:"AGE"`
+}
+
+func TestComposeBatchInsertSQL(t *testing.T) {
+	_s, err := analyseStruct(&batchPerson{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	_s.AppendBatchValue(map[string]any{"NAME": `O'Brien "Bob"`, "AGE": 18, "CREATED": created})
+	_s.AppendBatchValue(map[string]any{"NAME": "Mike", "AGE": 20, "CREATED": created})
+
+	sql, err := _s.composeBatchInsertSQL()
+	want := "INSERT INTO `Person` (`AGE`, `CREATED`, `NAME`) VALUES (?, ?, ?), (?, ?, ?);"
+	if err != nil || sql != want {
+		t.Fatalf("got %s, %v, want %s", sql, err, want)
+	}
+	wantValues := []any{18, "2023-01-02 03:04:05.000", `O'Brien "Bob"`, 20, "2023-01-02 03:04:05.000", "Mike"}
+	if !reflect.DeepEqual(_s.Values, wantValues) {
+		t.Fatalf("got %#v, want %#v", _s.Values, wantValues)
+	}
+}
+
+func TestComposeBatchUpdateSQL(t *testing.T) {
+	_s, err := analyseStruct(&batchPerson{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_s.AppendBatchValue(map[string]any{"INDEX": 1, "NAME": "a'b", "AGE": 18})
+	_s.AppendBatchValue(map[string]any{"INDEX": 2, "NAME": "c"})
+
+	sql, err := _s.composeBatchUpdateSQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "UPDATE `Person` SET `AGE` = CASE `ID` WHEN ? THEN ? ELSE `AGE` END, `NAME` = CASE `ID` WHEN ? THEN ? WHEN ? THEN ? ELSE `NAME` END WHERE `ID` IN (?, ?);"
+	if sql != want {
+		t.Fatalf("got %s, want %s", sql, want)
+	}
+	wantValues := []any{1, 18, 1, "a'b", 2, "c", 1, 2}
+	if !reflect.DeepEqual(_s.Values, wantValues) {
+		t.Fatalf("got %#v, want %#v", _s.Values, wantValues)
+	}
+}
+
+func TestComposeErrors(t *testing.T) {
+	type noIndex struct {
+		ID   int64  `COL:"ID" TABLE:"Person"`
+		Name string `COL:"NAME"`
+	}
+
+	stat := &QStat{dbc: &QData{}}
+	stat.SetModel(&[]noIndex{{ID: 1}, {ID: 2}})
+	if res := stat.Update(); !errors.Is(res.Error, ErrNoPrimaryKey) {
+		t.Fatalf("got %v, want %v", res.Error, ErrNoPrimaryKey)
+	}
+
+	stat.SetModel(noIndex{})
+	if res := stat.Query(); !errors.Is(res.Error, ErrNotSettable) {
+		t.Fatalf("got %v, want %v", res.Error, ErrNotSettable)
+	}
+
+	stat.SetModel([]noIndex{})
+	if !errors.Is(stat.Err(), ErrNotSettable) {
+		t.Fatalf("got %v, want %v", stat.Err(), ErrNotSettable)
+	}
+
+	stat.SetModel(1)
+	if res := stat.Insert(); !errors.Is(res.Error, ErrInvalidModel) {
+		t.Fatalf("got %v, want %v", res.Error, ErrInvalidModel)
+	}
+}
+
+type benchPerson struct {
+	ID      int64             `COL:"ID" TABLE:"Person" INDEX:""`
+	Name    QString           `COL:"NAME" ALT:""`
+	Age     int               `COL:"AGE" ASNULL:"-1"`
+	Profile string            `COL:"PROFILE" ALT:"{}" JSONARRAYAPPEND:"Profile, '$'"`
+	Log     []string          `JSON:"Json.log" JSONMERGEPRESERVE:"Json->>'$.log'" JSONCAST:"" INIT:"[]"`
+	Array   QStrings          `JSON:"Json.array" JSONCAST:""`
+	Extra   map[string]string `JSON:"Json.extra"`
+	Created time.Time         `COL:"CREATED" SCHEMAF:"DATETIME"`
+	Omit    string            `OMIT:""`
+}
+
+func TestAnalyseStructCache(t *testing.T) {
+	a, _ := analyseStruct(&benchPerson{}, nil)
+	b, _ := analyseStruct(&[]benchPerson{{}, {}}, nil)
+	if b.Length != 2 || len(a.Fields) != len(b.Fields) {
+		t.Fatalf("got %d fields (length %d), want %d fields", len(b.Fields), b.Length, len(a.Fields))
+	}
+
+	a.Fields[0].IsIndex = false
+	a.Fields[1].Self = "+1"
+	c, _ := analyseStruct(&benchPerson{}, nil)
+	if !c.Fields[0].IsIndex || c.Fields[1].Self != "" {
+		t.Fatal("the cached layout must not be modified by the statement")
+	}
+}
+
+func BenchmarkAnalyseStruct(b *testing.B) {
+	var per benchPerson
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		analyseStruct(&per, nil)
+	}
+}
+
+func BenchmarkAnalyseStructNoCache(b *testing.B) {
+	var per benchPerson
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parseStructMeta(structToValue(&per).Type(), nil)
+	}
+}
+
+type nestedAudit struct {
+	CreatedAt time.Time `COL:"CREATED_AT"`
+	UpdatedAt time.Time `COL:"UPDATED_AT"`
+}
+
+type nestedDept struct {
+	ID   int64  `COL:"ID"`
+	Name string `COL:"NAME"`
+}
+
+type nestedPerson struct {
+	ID   int64      `COL:"ID" TABLE:"Person" INDEX:""`
+	Name string     `COL:"NAME" JOIN:"LEFT JOIN Dept ON Dept.ID = Person.DEPT_ID"`
+	Dept nestedDept `TABLE:"Dept"`
+	Boss nestedDept `TABLE:"Person" PREFIX:"BOSS_"`
+	nestedAudit
+}
+
+func TestNestedStruct(t *testing.T) {
+	var query string
+	db := newFakeData(t, Config{}, func(q string, args []driver.NamedValue) fakeResponse {
+		query = q
+		return fakeResponse{
+			Columns: []string{"ID", "NAME", "ID", "NAME", "BOSS_ID", "BOSS_NAME", "CREATED_AT", "UPDATED_AT"},
+			Rows: [][]driver.Value{{[]byte("1"), []byte("Mike"), []byte("2"), []byte("R&D"), []byte("3"), []byte("Jane"),
+				[]byte("2024-01-02 03:04:05"), []byte("2024-01-02 03:04:05")}},
+		}
+	})
+
+	var per nestedPerson
+	stat := db.Model(&per)
+	if res := stat.Query(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	want := " SELECT `Person`.`ID`, `Person`.`NAME`, `Dept`.`ID`, `Dept`.`NAME`, `Person`.`BOSS_ID`, `Person`.`BOSS_NAME`, `Person`.`CREATED_AT`, `Person`.`UPDATED_AT` FROM `Person` LEFT JOIN Dept ON Dept.ID = Person.DEPT_ID WHERE (`Person`.`ID` IN (?)) LIMIT 1"
+	if query != want {
+		t.Fatalf("got %s, want %s", query, want)
+	}
+	if per.Dept.Name != "R&D" || per.Boss.ID != 3 || per.Boss.Name != "Jane" || per.CreatedAt.Year() != 2024 {
+		t.Fatalf("got %+v", per)
+	}
+
+	per.Boss.Name = "Tom"
+	if changes := stat.Changes(); len(changes) != 1 || changes["Boss.Name"].Column != "BOSS_NAME" {
+		t.Fatalf("got %v", changes)
+	}
+}
@@ -0,0 +1,144 @@
+package dataq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// qUnion is the composed ` UNION [ALL] SELECT ...` part of the statement
+type qUnion struct {
+	SQL    string
+	Values []any
+}
+
+type qInSub struct {
+	col string
+	sub *QStat
+}
+
+// InSub is `col IN (SELECT ...)`, the values of the subquery are merged in order
+func InSub(col string, sub *QStat) QCond {
+	return qInSub{col, sub}
+}
+
+func (c qInSub) build(dialect Dialect) (string, []any, error) {
+	_sql, vals, err := c.sub.subquery()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("%s IN (%s)", quoteColumn(dialect, c.col), _sql), vals, nil
+}
+
+// subquery composes the SELECT statement of sub to be embedded in another statement,
+// the placeholders are rebound by the outer statement
+// Use a slice model, e.g. Model(&[]Order{}), as the one of a struct is limited to 1 row
+func (sub *QStat) subquery() (string, []any, error) {
+	if sub.err != nil {
+		return "", nil, sub.err
+	}
+	sub.Method = sqlSelect
+
+	_sql, err := sub.composeSQL()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return strings.TrimSpace(sub.replaceVariables(_sql)), append([]any(nil), sub.sqlStruct.Values...), nil
+}
+
+// subError keeps the first error of the subqueries
+func (stat *QStat) subError(err error) *QStat {
+	if stat.err == nil {
+		stat.err = fmt.Errorf("dataq: subquery: %w", err)
+	}
+
+	return stat
+}
+
+// WithStat adds the common table expression `name AS (SELECT ...)` of sub, see With
+func (stat *QStat) WithStat(name string, sub *QStat) *QStat {
+	_sql, vals, err := sub.subquery()
+	if err != nil {
+		return stat.subError(err)
+	}
+	stat.With(fmt.Sprintf("%s AS (%s)", stat.sqlStruct.quote(name), _sql))
+	stat.sqlStruct.WithValues = append(stat.sqlStruct.WithValues, vals...)
+
+	return stat
+}
+
+// FromSub selects FROM (SELECT ...) AS alias instead of the table,
+// the alias is usually the table of the model as the columns are qualified with it
+func (stat *QStat) FromSub(sub *QStat, alias string) *QStat {
+	_sql, vals, err := sub.subquery()
+	if err != nil {
+		return stat.subError(err)
+	}
+	stat.sqlStruct.FromSub = fmt.Sprintf("(%s) AS %s", _sql, stat.sqlStruct.quote(alias))
+	stat.sqlStruct.FromValues = vals
+
+	return stat
+}
+
+// JoinSub joins (SELECT ...) AS alias, e.g. JoinSub("LEFT JOIN", sub, "O", "O.USER_ID = User.ID")
+func (stat *QStat) JoinSub(join string, sub *QStat, alias, on string) *QStat {
+	_sql, vals, err := sub.subquery()
+	if err != nil {
+		return stat.subError(err)
+	}
+	stat.sqlStruct.Joins = append(stat.sqlStruct.Joins, fmt.Sprintf("%s (%s) AS %s ON %s", join, _sql, stat.sqlStruct.quote(alias), on))
+	stat.sqlStruct.JoinValues = append(stat.sqlStruct.JoinValues, vals...)
+
+	return stat
+}
+
+// Union appends `UNION SELECT ...` of the subs, ORDER BY and LIMIT of the statement apply to the whole result
+// The subs must select the same columns as the model
+func (stat *QStat) Union(subs ...*QStat) *QStat {
+	return stat.union("UNION", subs)
+}
+
+// UnionAll appends `UNION ALL SELECT ...` of the subs, see Union
+func (stat *QStat) UnionAll(subs ...*QStat) *QStat {
+	return stat.union("UNION ALL", subs)
+}
+
+// composeUnionSQL returns the SELECT statement with GROUP BY, HAVING and the unions, without ORDER BY and LIMIT
+func (stat *QStat) composeUnionSQL() string {
+	var (
+		sql strings.Builder
+	)
+
+	sql.WriteString(stat.sqlStruct.composeSelectSQL(stat.Filters))
+
+	if stat.GroupS != "" {
+		sql.WriteString(fmt.Sprintf(" %v", stat.GroupS))
+	}
+
+	if stat.HavingS != "" {
+		sql.WriteString(fmt.Sprintf(" HAVING %v", stat.HavingS))
+	}
+
+	for _, _union := range stat.unions {
+		sql.WriteString(_union.SQL)
+		stat.sqlStruct.Values = append(stat.sqlStruct.Values, _union.Values...)
+	}
+
+	return sql.String()
+}
+
+func (stat *QStat) union(operator string, subs []*QStat) *QStat {
+	for _, _sub := range subs {
+		_sql, vals, err := _sub.subquery()
+		if err != nil {
+			return stat.subError(err)
+		}
+		stat.unions = append(stat.unions, qUnion{
+			SQL:    fmt.Sprintf(" %s %s", operator, _sql),
+			Values: vals,
+		})
+	}
+
+	return stat
+}
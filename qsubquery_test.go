@@ -0,0 +1,64 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type subOrder struct {
+	UserID int64 `COL:"USER_ID" TABLE:"Order"`
+}
+
+func TestSubquery(t *testing.T) {
+	var (
+		query string
+		args  []any
+	)
+	db := newFakeData(t, Config{}, func(q string, a []driver.NamedValue) fakeResponse {
+		query, args = q, nil
+		for _, _arg := range a {
+			args = append(args, _arg.Value)
+		}
+		if strings.HasPrefix(q, "SELECT COUNT") {
+			return fakeResponse{Columns: []string{"COUNT"}, Rows: [][]driver.Value{{[]byte("2")}}}
+		}
+		return fakeResponse{Columns: []string{"ID", "NAME", "AGE"}}
+	})
+
+	var persons []batchPerson
+	res := db.Model(&persons).
+		WithStat("Adult", db.Model(&[]batchPerson{}).WhereCond(Gte("AGE", 18))).
+		FromSub(db.Model(&[]batchPerson{}).WhereCond(Neq("NAME", "x")), "Person").
+		JoinSub("JOIN", db.Model(&[]subOrder{}).WhereCond(Gt("USER_ID", 1)), "O", "O.USER_ID = Person.ID").
+		WhereCond(InSub("Person.ID", db.Model(&[]subOrder{}).WhereCond(Lt("USER_ID", 2)))).
+		UnionAll(db.Model(&[]batchPerson{}).WhereCond(Eq("AGE", 3))).
+		Limit(4).
+		Query()
+	want := "WITH `Adult` AS (SELECT `Person`.`ID`, `Person`.`NAME`, `Person`.`AGE` FROM `Person` WHERE (`AGE` >= ?))" +
+		" SELECT `Person`.`ID`, `Person`.`NAME`, `Person`.`AGE`" +
+		" FROM (SELECT `Person`.`ID`, `Person`.`NAME`, `Person`.`AGE` FROM `Person` WHERE (`NAME` <> ?)) AS `Person`" +
+		" JOIN (SELECT `Order`.`USER_ID` FROM `Order` WHERE (`USER_ID` > ?)) AS `O` ON O.USER_ID = Person.ID" +
+		" WHERE (`Person`.`ID` IN (SELECT `Order`.`USER_ID` FROM `Order` WHERE (`USER_ID` < ?)))" +
+		" UNION ALL SELECT `Person`.`ID`, `Person`.`NAME`, `Person`.`AGE` FROM `Person` WHERE (`AGE` = ?) LIMIT ?"
+	if res.Error != nil || query != want {
+		t.Fatalf("got %s, %v, want %s", query, res.Error, want)
+	}
+	if wantArgs := []any{int64(18), "x", int64(1), int64(2), int64(3), int64(4)}; !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("got %v, want %v", args, wantArgs)
+	}
+
+	// Count of the union counts the rows of the whole union
+	res = db.Model(&[]batchPerson{}).WhereCond(Eq("AGE", 1)).Union(db.Model(&[]batchPerson{}).WhereCond(Eq("AGE", 2))).Count()
+	want = "SELECT COUNT(1) FROM (SELECT `Person`.`ID`, `Person`.`NAME`, `Person`.`AGE` FROM `Person` WHERE (`AGE` = ?)" +
+		" UNION SELECT `Person`.`ID`, `Person`.`NAME`, `Person`.`AGE` FROM `Person` WHERE (`AGE` = ?)) AS c"
+	if res.Error != nil || res.ReturnedRows != 2 || query != want || !reflect.DeepEqual(args, []any{int64(1), int64(2)}) {
+		t.Fatalf("got %s %v, %v, want %s", query, args, res.Error, want)
+	}
+
+	if res := db.Model(&persons).WhereCond(InSub("ID", db.Model(1))).Query(); !errors.Is(res.Error, ErrInvalidModel) {
+		t.Fatalf("got %v, want ErrInvalidModel", res.Error)
+	}
+}
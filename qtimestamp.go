@@ -0,0 +1,66 @@
+package dataq
+
+import (
+	"reflect"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// now returns the time of Config.Clock in Config.Location
+func (dbc *QData) now() time.Time {
+	now := time.Now
+	if dbc.config.Clock != nil {
+		now = dbc.config.Clock
+	}
+	if dbc.config.Location != nil {
+		return now().In(dbc.config.Location)
+	}
+
+	return now()
+}
+
+// fillTimestamps sets the `AUTOCREATE` (if zero) and `AUTOUPDATE` fields of each row before INSERT,
+// and the `AUTOUPDATE` fields before UPDATE
+func (stat *QStat) fillTimestamps() {
+	var hasAuto bool
+	for _, _field := range stat.sqlStruct.Fields {
+		if _field.AutoCreate && stat.Method == sqlInsert || _field.AutoUpdate && (stat.Method == sqlInsert || stat.Method == sqlUpdate) {
+			hasAuto = true
+			break
+		}
+	}
+	if !hasAuto {
+		return
+	}
+	stat.sqlStruct.makeAddressable()
+
+	now := stat.dbc.now()
+	for i := 0; i < stat.sqlStruct.rowCount(); i++ {
+		row := stat.sqlStruct.getRowValue(i)
+		for _, _field := range stat.sqlStruct.Fields {
+			fieldValue := row.FieldByIndex(_field.ValIdx)
+			switch {
+			case stat.Method == sqlInsert && (_field.AutoCreate || _field.AutoUpdate) && fieldValue.IsZero():
+				setTime(fieldValue, now)
+			case stat.Method == sqlUpdate && _field.AutoUpdate:
+				setTime(fieldValue, now)
+			}
+		}
+	}
+}
+
+// setTime sets the time.Time, *time.Time, or the field with the method Set(time.Time), e.g. QTime and QNull[time.Time]
+func setTime(fieldValue reflect.Value, t time.Time) {
+	switch {
+	case fieldValue.Type() == timeType:
+		fieldValue.Set(reflect.ValueOf(t))
+	case fieldValue.Kind() == reflect.Ptr && fieldValue.Type().Elem() == timeType:
+		fieldValue.Set(reflect.ValueOf(&t))
+	case fieldValue.CanAddr():
+		setter := fieldValue.Addr().MethodByName("Set")
+		if setter.IsValid() && setter.Type().NumIn() == 1 && setter.Type().In(0) == timeType {
+			setter.Call([]reflect.Value{reflect.ValueOf(t)})
+		}
+	}
+}
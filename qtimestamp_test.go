@@ -0,0 +1,47 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+type stampedPerson struct {
+	ID      int64            `COL:"ID" TABLE:"Person" INDEX:""`
+	Name    string           `COL:"NAME"`
+	Created time.Time        `COL:"CREATED" AUTOCREATE:""`
+	Updated *time.Time       `COL:"UPDATED" AUTOUPDATE:""`
+	Seen    QNull[time.Time] `JSON:"INFO.seen" AUTOUPDATE:""`
+}
+
+func TestTimestamps(t *testing.T) {
+	var lastArgs []driver.NamedValue
+	db := newFakeData(t, Config{
+		Clock:    func() time.Time { return time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC) },
+		Location: time.FixedZone("CEST", 2*60*60),
+	}, func(query string, args []driver.NamedValue) fakeResponse {
+		lastArgs = args
+		return fakeResponse{AffectedRows: 1}
+	})
+
+	per := stampedPerson{ID: 1, Name: "Mike"}
+	if res := db.Model(&per).Insert(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	want := time.Date(2024, 5, 6, 9, 8, 9, 0, time.FixedZone("CEST", 2*60*60))
+	if !per.Created.Equal(want) || per.Created.Location().String() != "CEST" || per.Updated == nil || !per.Updated.Equal(want) || !per.Seen.Valid {
+		t.Fatalf("got %v, %v, %v", per.Created, per.Updated, per.Seen)
+	}
+	if len(lastArgs) != 5 || lastArgs[2].Value != "2024-05-06 09:08:09.000" {
+		t.Fatalf("got %v", lastArgs)
+	}
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	persons := []stampedPerson{{ID: 1, Created: created}, {ID: 2, Created: created}}
+	if res := db.Model(persons).Update(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if !persons[0].Created.Equal(created) || persons[1].Updated == nil || !persons[1].Updated.Equal(want) {
+		t.Fatalf("got %v", persons)
+	}
+}
@@ -0,0 +1,89 @@
+package dataq
+
+import (
+	"reflect"
+)
+
+// QChange is the change of a field since the model is queried
+type QChange struct {
+	Column string
+	Old    any
+	New    any
+}
+
+// takeSnapshot remembers the values of the first n rows, which are compared by Update
+func (_s *qStruct) takeSnapshot(n int) {
+	if n > _s.rowCount() {
+		n = _s.rowCount()
+	}
+	_s.snapshot = make([][]any, n)
+	for i := 0; i < n; i++ {
+		// the values to be bound are immutable, e.g. the JSON string of the map
+		row := make([]any, len(_s.Fields))
+		for _j, _field := range _s.Fields {
+			row[_j] = _s.getValueInterface(_field.ValIdx, i)
+		}
+		_s.snapshot[i] = row
+	}
+}
+
+// rowCount returns the number of rows in the model
+func (_s *qStruct) rowCount() int {
+	if _s.Value.Kind() == reflect.Slice {
+		return _s.Value.Len()
+	}
+
+	return 1
+}
+
+// isChanged reports whether the field of the row is changed since the snapshot,
+// tracked is false if the row has no snapshot
+func (_s *qStruct) isChanged(i, j int) (changed, tracked bool) {
+	if i >= len(_s.snapshot) || i >= _s.rowCount() {
+		return false, false
+	}
+
+	return !reflect.DeepEqual(_s.snapshot[i][j], _s.getValueInterface(_s.Fields[j].ValIdx, i)), true
+}
+
+// Changes returns the changed fields of the (first) row since Query, keyed by the field name
+// It is nil if the model is not queried
+func (stat *QStat) Changes() map[string]QChange {
+	return stat.ChangesAt(0)
+}
+
+// ChangesAt returns the changed fields of the i-th row since Query, keyed by the field name
+func (stat *QStat) ChangesAt(i int) map[string]QChange {
+	_s := &stat.sqlStruct
+	if i >= len(_s.snapshot) || i >= _s.rowCount() {
+		return nil
+	}
+
+	changes := make(map[string]QChange)
+	elemType := _s.getElemType()
+	for _j, _field := range _s.Fields {
+		if changed, _ := _s.isChanged(i, _j); changed {
+			column := _field.ColName
+			if _field.Json != "" {
+				column += "." + _field.Json
+			}
+			changes[fieldName(elemType, _field.ValIdx)] = QChange{
+				Column: column,
+				Old:    _s.snapshot[i][_j],
+				New:    _s.getValueInterface(_field.ValIdx, i),
+			}
+		}
+	}
+
+	return changes
+}
+
+// updateSnapshot remembers the rows queried, or the rows updated if they are tracked
+func (stat *QStat) updateSnapshot(res *QResult) {
+	switch {
+	case stat.Method == sqlSelect:
+		stat.sqlStruct.takeSnapshot(int(res.ReturnedRows))
+	case stat.Method == sqlUpdate && len(stat.sqlStruct.snapshot) > 0:
+		stat.sqlStruct.takeSnapshot(len(stat.sqlStruct.snapshot))
+	}
+}
@@ -0,0 +1,130 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type trackedPerson struct {
+	ID   int64  `COL:"ID" TABLE:"Person" INDEX:""`
+	Name string `COL:"NAME"`
+	Age  int    `COL:"AGE"`
+}
+
+func TestChanges(t *testing.T) {
+	var (
+		queries []string
+		args    []driver.NamedValue
+	)
+	db := newFakeData(t, Config{}, func(query string, a []driver.NamedValue) fakeResponse {
+		if strings.HasPrefix(strings.TrimSpace(query), "SELECT") {
+			res := fakeResponse{
+				Columns: []string{"ID", "NAME", "AGE"},
+				Rows:    [][]driver.Value{{[]byte("1"), []byte("Mike"), []byte("30")}, {[]byte("2"), []byte("Jane"), []byte("20")}},
+			}
+			if strings.HasSuffix(query, "LIMIT 1") {
+				res.Rows = res.Rows[:1]
+			}
+			return res
+		}
+		queries, args = append(queries, query), a
+		return fakeResponse{AffectedRows: 1}
+	})
+
+	per := trackedPerson{ID: 1}
+	stat := db.Model(&per)
+	if res := stat.Query(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if changes := stat.Changes(); len(changes) != 0 {
+		t.Fatalf("got %v, want no changes", changes)
+	}
+
+	per.Age = 0
+	want := map[string]QChange{"Age": {Column: "AGE", Old: 30, New: 0}}
+	if changes := stat.Changes(); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("got %#v, want %#v", changes, want)
+	}
+	if res := stat.Update(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if queries[0] != "UPDATE `Person` SET `AGE`=? WHERE (`ID`=?)" || args[0].Value != int64(0) {
+		t.Fatalf("got %s %v", queries[0], args)
+	}
+
+	// nothing changed since the update
+	if res := stat.Update(); res.Error != nil || len(queries) != 1 || len(stat.Changes()) != 0 {
+		t.Fatalf("got %v, %v", res.Error, queries)
+	}
+
+	persons := make([]trackedPerson, 2)
+	stat = db.Model(&persons)
+	if res := stat.Query(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	persons[1].Name = ""
+	if res := stat.Update(); res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if queries[1] != "UPDATE `Person` SET `NAME`=CASE `ID` WHEN ? THEN ? ELSE `NAME` END WHERE `ID` IN (?,?)" || args[1].Value != "" {
+		t.Fatalf("got %s %v", queries[1], args)
+	}
+
+	// the growable slice is filled by Query
+	var grown []trackedPerson
+	stat = db.Model(&grown)
+	if res := stat.Query(); res.Error != nil || len(grown) != 2 {
+		t.Fatalf("got %v, %v", res.Error, grown)
+	}
+	grown[1].Age = 0
+	if res := stat.Update(); res.Error != nil || res.AffectedRows != 1 {
+		t.Fatalf("got %v, %d", res.Error, res.AffectedRows)
+	}
+	if len(queries) != 3 || queries[2] != "UPDATE `Person` SET `AGE`=CASE `ID` WHEN ? THEN ? ELSE `AGE` END WHERE `ID` IN (?,?)" || args[1].Value != int64(0) {
+		t.Fatalf("got %q %v", queries, args)
+	}
+}
+
+type clearPerson struct {
+	ID   int64  `COL:"ID" TABLE:"Person" INDEX:""`
+	Name string `COL:"NAME"`
+	Note string `COL:"NOTE" ASCLEAR:"-"`
+}
+
+func TestChangesForced(t *testing.T) {
+	var (
+		queries []string
+		args    []driver.NamedValue
+	)
+	db := newFakeData(t, Config{}, func(query string, a []driver.NamedValue) fakeResponse {
+		if strings.HasPrefix(strings.TrimSpace(query), "SELECT") {
+			return fakeResponse{
+				Columns: []string{"ID", "NAME", "NOTE"},
+				Rows:    [][]driver.Value{{[]byte("1"), []byte("Mike"), []byte("-")}},
+			}
+		}
+		queries, args = append(queries, query), a
+		return fakeResponse{AffectedRows: 1}
+	})
+
+	// the same forcing rules as the untracked update: IgnoreNullAt and the ASCLEAR value
+	for _, tracked := range []bool{false, true} {
+		queries = nil
+		per := clearPerson{ID: 1, Name: "Mike", Note: "-"}
+		stat := db.Model(&per)
+		if tracked {
+			if res := stat.Query(); res.Error != nil {
+				t.Fatal(res.Error)
+			}
+		}
+		per.Name = ""
+		if res := stat.IgnoreNullAt(1).Update(); res.Error != nil {
+			t.Fatal(res.Error)
+		}
+		if len(queries) != 1 || queries[0] != "UPDATE `Person` SET `NAME`=?, `NOTE`=? WHERE (`ID`=?)" || args[0].Value != "" || args[1].Value != "" {
+			t.Fatalf("tracked %v: got %q %v", tracked, queries, args)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package dataq
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	DefaultTxMaxRetries   = 3
+	DefaultTxRetryBackoff = 50 * time.Millisecond
+)
+
+// Transact runs fn in a transaction started with opts, see TransactN
+func (c *QData) Transact(opts *sql.TxOptions, fn func(tx *QData) error) error {
+	_, err := c.TransactN(opts, fn)
+
+	return err
+}
+
+// TransactN runs fn in a transaction started with opts (isolation level, read-only),
+// it commits if fn returns nil, otherwise rolls back
+// The whole fn is retried with the exponential backoff when the transaction is deadlocked or the lock wait is timeout,
+// up to Config.TxMaxRetries times, the attempts used are returned
+// If c is a transaction handler, fn runs once in a savepoint as the deadlock rolls back the outer transaction,
+// the options can not be set on a savepoint, ErrSavepointOptions is returned if opts is not nil
+func (c *QData) TransactN(opts *sql.TxOptions, fn func(tx *QData) error) (attempts int, err error) {
+	if c.tx != nil && opts != nil {
+		return 0, ErrSavepointOptions
+	}
+	maxRetries := c.config.TxMaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultTxMaxRetries
+	}
+	if maxRetries < 0 || c.tx != nil {
+		maxRetries = 0
+	}
+	backoff := c.config.TxRetryBackoff
+	if backoff == 0 {
+		backoff = DefaultTxRetryBackoff
+	}
+
+	ctx := c.Context()
+	for {
+		attempts++
+		err = c.transactOnce(opts, fn)
+		if err == nil || attempts > maxRetries || !isRetryable(err) {
+			return attempts, err
+		}
+
+		timer := time.NewTimer(backoff << (attempts - 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *QData) transactOnce(opts *sql.TxOptions, fn func(tx *QData) error) error {
+	tx, err := c.BeginTx(c.Context(), opts)
+	if err != nil {
+		return err
+	}
+
+	return tx.FinAfterFuncOK(func() error {
+		return fn(tx)
+	})
+}
+
+// isRetryable reports whether the transaction failed by a deadlock or a lock wait timeout
+func isRetryable(err error) bool {
+	return IsDeadlock(err) || IsLockWaitTimeout(err)
+}
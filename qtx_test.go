@@ -0,0 +1,69 @@
+package dataq
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestTransactRetry(t *testing.T) {
+	var (
+		queries []string
+		updates int
+	)
+	db := newFakeData(t, Config{TxMaxRetries: 2, TxRetryBackoff: time.Millisecond}, func(query string, args []driver.NamedValue) fakeResponse {
+		queries = append(queries, query)
+		if strings.HasPrefix(query, "UPDATE") {
+			updates++
+			if updates < 3 || strings.Contains(query, "NAME") {
+				return fakeResponse{Err: &mysql.MySQLError{Number: mysqlErrDeadlock, Message: "Deadlock found"}}
+			}
+		}
+		return fakeResponse{AffectedRows: 1}
+	})
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	attempts, err := db.TransactN(opts, func(tx *QData) error {
+		_, err := tx.ExecUnsafe("UPDATE Person SET AGE = AGE + 1")
+		return err
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("got %d, %v, want 3 attempts", attempts, err)
+	}
+	if queries[0] != "BEGIN ISOLATION LEVEL Serializable" || queries[len(queries)-1] != "COMMIT" {
+		t.Fatalf("got %v", queries)
+	}
+
+	// the savepoint is not retried alone
+	nested := 0
+	attempts, err = db.TransactN(opts, func(tx *QData) error {
+		return tx.Transact(nil, func(tx *QData) error {
+			nested++
+			_, err := tx.ExecUnsafe("UPDATE Person SET NAME = 'Mike'")
+			return err
+		})
+	})
+	if !isRetryable(err) || attempts != 3 || nested != 3 {
+		t.Fatalf("got %d (%d nested), %v, want 3 attempts and deadlock", attempts, nested, err)
+	}
+
+	errFn := errors.New("fn")
+	attempts, err = db.TransactN(&sql.TxOptions{ReadOnly: true}, func(tx *QData) error {
+		return errFn
+	})
+	if !errors.Is(err, errFn) || attempts != 1 {
+		t.Fatalf("got %d, %v, want 1 attempt", attempts, err)
+	}
+
+	err = db.Transact(nil, func(tx *QData) error {
+		return tx.Transact(opts, func(tx *QData) error { return nil })
+	})
+	if !errors.Is(err, ErrSavepointOptions) {
+		t.Fatalf("got %v, want ErrSavepointOptions", err)
+	}
+}
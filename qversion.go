@@ -0,0 +1,52 @@
+package dataq
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// versionField returns the `VERSION` field of the model
+func (_s *qStruct) versionField() (qField, bool) {
+	for _, _field := range _s.Fields {
+		if _field.Version {
+			return _field, true
+		}
+	}
+
+	return qField{}, false
+}
+
+// composeVersionSet returns `version = version + 1`
+func (_s *qStruct) composeVersionSet(_field qField) string {
+	col := _s.quote(_field.ColName)
+
+	return fmt.Sprintf("%s=%s + 1", col, col)
+}
+
+// checkVersion returns ErrStaleObject if any row is not updated,
+// otherwise increments the `VERSION` field of each row
+func (stat *QStat) checkVersion(res *QResult) error {
+	_field, ok := stat.sqlStruct.versionField()
+	if !ok {
+		return nil
+	}
+	n := stat.sqlStruct.rowCount()
+	if res.AffectedRows < int64(n) {
+		return fmt.Errorf("%w: %d of %d rows updated", ErrStaleObject, res.AffectedRows, n)
+	}
+
+	for i := 0; i < n; i++ {
+		fieldValue := stat.sqlStruct.getRowValue(i).FieldByIndex(_field.ValIdx)
+		if !fieldValue.CanSet() {
+			continue
+		}
+		switch fieldValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fieldValue.SetInt(fieldValue.Int() + 1)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fieldValue.SetUint(fieldValue.Uint() + 1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package dataq
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type versionedPerson struct {
+	ID      int64  `COL:"ID" TABLE:"Person" INDEX:""`
+	Name    string `COL:"NAME"`
+	Version int    `COL:"VERSION" VERSION:""`
+}
+
+func TestVersion(t *testing.T) {
+	var (
+		query    string
+		args     []driver.NamedValue
+		affected int64
+	)
+	db := newFakeData(t, Config{}, func(q string, a []driver.NamedValue) fakeResponse {
+		query, args = q, a
+		return fakeResponse{AffectedRows: affected}
+	})
+
+	per := versionedPerson{ID: 1, Name: "Mike", Version: 3}
+	affected = 1
+	if res := db.Model(&per).Update(); res.Error != nil || per.Version != 4 {
+		t.Fatalf("got %v, version %d", res.Error, per.Version)
+	}
+	want := "UPDATE `Person` SET `NAME`=?, `VERSION`=`VERSION` + 1 WHERE (`ID`=?) AND (`VERSION`=?)"
+	if query != want || len(args) != 3 || args[2].Value != int64(3) {
+		t.Fatalf("got %s %v, want %s", query, args, want)
+	}
+
+	affected = 0
+	if res := db.Model(&per).Update(); !errors.Is(res.Error, ErrStaleObject) || per.Version != 4 {
+		t.Fatalf("got %v, version %d, want %v", res.Error, per.Version, ErrStaleObject)
+	}
+
+	persons := []versionedPerson{{ID: 1, Name: "a", Version: 1}, {ID: 2, Name: "b", Version: 5}}
+	affected = 2
+	if res := db.Model(persons).Update(); res.Error != nil || persons[0].Version != 2 || persons[1].Version != 6 {
+		t.Fatalf("got %v, %v", res.Error, persons)
+	}
+	want = "UPDATE `Person` SET `NAME`=CASE `ID` WHEN ? THEN ? WHEN ? THEN ? ELSE `NAME` END,`VERSION`=`VERSION` + 1 WHERE (`ID`=? AND `VERSION`=?) OR (`ID`=? AND `VERSION`=?)"
+	if query != want || len(args) != 8 || args[7].Value != int64(5) {
+		t.Fatalf("got %s %v, want %s", query, args, want)
+	}
+
+	affected = 1
+	if res := db.Model(persons).Update(); !errors.Is(res.Error, ErrStaleObject) {
+		t.Fatalf("got %v, want %v", res.Error, ErrStaleObject)
+	}
+}
@@ -0,0 +1,57 @@
+package dataq
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSavepoint(t *testing.T) {
+	var queries []string
+	db := newFakeData(t, Config{}, func(query string, args []driver.NamedValue) fakeResponse {
+		queries = append(queries, query)
+		return fakeResponse{AffectedRows: 1}
+	})
+
+	tx := db.Begin()
+	if !tx.InTx() {
+		t.Fatal(tx.Err())
+	}
+	errInner := errors.New("inner")
+	err := tx.FinAfterFuncOK(func() error {
+		if err := tx.Begin().FinAfterFuncOK(func() error { return nil }); err != nil {
+			return err
+		}
+		sp := tx.Begin()
+		if err := sp.FinAfterFuncOK(func() error {
+			return sp.Begin().FinAfterFuncOK(func() error { return errInner })
+		}); !errors.Is(err, errInner) {
+			t.Errorf("got %v, want %v", err, errInner)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "BEGIN; SAVEPOINT sp_1; RELEASE SAVEPOINT sp_1; SAVEPOINT sp_2; SAVEPOINT sp_3; ROLLBACK TO SAVEPOINT sp_3; ROLLBACK TO SAVEPOINT sp_2; COMMIT"
+	if got := strings.Join(queries, "; "); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if tx.InTx() {
+		t.Fatal("transaction is not finished")
+	}
+
+	queries = nil
+	tx = db.Begin()
+	if _, err := tx.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true}); !errors.Is(err, ErrSavepointOptions) {
+		t.Fatalf("got %v, want ErrSavepointOptions", err)
+	}
+	if err := tx.Rollback(); err != nil || strings.Join(queries, "; ") != "BEGIN; ROLLBACK" {
+		t.Fatalf("got %v, %s", err, strings.Join(queries, "; "))
+	}
+}
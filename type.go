@@ -1,11 +1,164 @@
 package dataq
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strconv"
 	"time"
 )
 
+// QNull is the nullable value of T,
+// the invalid value is scanned from NULL, written as NULL and skipped by Update()
+type QNull[T any] struct {
+	V     T
+	Valid bool
+}
+
+func NewQNull[T any](value T) *QNull[T] {
+	return &QNull[T]{
+		V:     value,
+		Valid: true,
+	}
+}
+
+func InitQNull[T any](value T) QNull[T] {
+	return QNull[T]{
+		V:     value,
+		Valid: true,
+	}
+}
+
+// Set the value and force to update
+func (t *QNull[T]) Set(value T) *QNull[T] {
+	t.V = value
+	t.Valid = true
+
+	return t
+}
+
+// Clear sets the value to NULL
+func (t *QNull[T]) Clear() *QNull[T] {
+	var zero T
+	t.V = zero
+	t.Valid = false
+
+	return t
+}
+
+// Scan implements the sql.Scanner
+func (t *QNull[T]) Scan(src any) (err error) {
+	t.Valid, err = scanNull(&t.V, src)
+
+	return err
+}
+
+// Value implements the driver.Valuer
+func (t QNull[T]) Value() (driver.Value, error) {
+	return nullValue(t.V, t.Valid)
+}
+
+func (t QNull[T]) MarshalBinary() ([]byte, error) {
+	return t.MarshalJSON()
+}
+
+func (t *QNull[T]) UnmarshalBinary(data []byte) error {
+	return t.UnmarshalJSON(data)
+}
+
+func (t QNull[T]) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(t.V)
+}
+
+func (t *QNull[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		t.Clear()
+		return nil
+	}
+	err := json.Unmarshal(data, &t.V)
+	t.Valid = (err == nil)
+
+	return err
+}
+
+func (t QNull[T]) String() string {
+	if !t.Valid {
+		return ""
+	}
+	switch _val := any(t.V).(type) {
+	case time.Time:
+		return _val.Format(ConfigParseDateTimeFormat)
+	case float32, float64:
+		return fmt.Sprintf("%f", _val)
+	}
+
+	return fmt.Sprint(t.V)
+}
+
+// qValuer is implemented by the Q* types whose field `Value` prevents them from being a driver.Valuer
+type qValuer interface {
+	qValue() (driver.Value, error)
+}
+
+// scanNull decodes src into the pointer dest, it returns false for NULL
+func scanNull(dest any, src any) (bool, error) {
+	destValue := reflect.ValueOf(dest).Elem()
+	if src == nil {
+		destValue.Set(reflect.Zero(destValue.Type()))
+		return false, nil
+	}
+
+	switch _src := src.(type) {
+	case []byte:
+		return true, setFieldValue(destValue, _src, false)
+	case string:
+		return true, setFieldValue(destValue, []byte(_src), false)
+	case time.Time:
+		if destValue.Kind() == reflect.String {
+			destValue.SetString(_src.Format(ConfigMySQLDateTimeFormat))
+			return true, nil
+		}
+	case int64:
+		if destValue.Kind() == reflect.String {
+			destValue.SetString(strconv.FormatInt(_src, 10))
+			return true, nil
+		}
+	case float64:
+		if destValue.Kind() == reflect.String {
+			destValue.SetString(strconv.FormatFloat(_src, 'f', -1, 64))
+			return true, nil
+		}
+	case bool:
+		if destValue.Kind() == reflect.String {
+			destValue.SetString(strconv.FormatBool(_src))
+			return true, nil
+		}
+	}
+
+	srcValue := reflect.ValueOf(src)
+	if destValue.Kind() != reflect.String && srcValue.Type().ConvertibleTo(destValue.Type()) {
+		destValue.Set(srcValue.Convert(destValue.Type()))
+		return true, nil
+	}
+
+	return false, fmt.Errorf("dataq: can not scan %T into %s", src, destValue.Type())
+}
+
+// nullValue converts the value to driver.Value, nil if it is not valid
+func nullValue(value any, valid bool) (driver.Value, error) {
+	if !valid {
+		return nil, nil
+	}
+
+	return driver.DefaultParameterConverter.ConvertValue(valueToSQL(reflect.ValueOf(value)))
+}
+
+// Deprecated: use QNull[bool]
 type QBool struct {
 	Value bool
 	Valid bool
@@ -63,10 +216,22 @@ func (t *QBool) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// Scan implements the sql.Scanner
+func (t *QBool) Scan(src any) (err error) {
+	t.Valid, err = scanNull(&t.Value, src)
+
+	return err
+}
+
+func (t QBool) qValue() (driver.Value, error) {
+	return nullValue(t.Value, t.Valid)
+}
+
 func (t QBool) String() string {
 	return fmt.Sprintf("%t", t.Value)
 }
 
+// Deprecated: use QNull[int]
 type QInt struct {
 	Value int
 	Valid bool
@@ -116,10 +281,22 @@ func (t *QInt) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// Scan implements the sql.Scanner
+func (t *QInt) Scan(src any) (err error) {
+	t.Valid, err = scanNull(&t.Value, src)
+
+	return err
+}
+
+func (t QInt) qValue() (driver.Value, error) {
+	return nullValue(t.Value, t.Valid)
+}
+
 func (t QInt) String() string {
 	return fmt.Sprintf("%d", t.Value)
 }
 
+// Deprecated: use QNull[float64]
 type QFloat64 struct {
 	Value float64
 	Valid bool
@@ -168,10 +345,22 @@ func (t *QFloat64) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// Scan implements the sql.Scanner
+func (t *QFloat64) Scan(src any) (err error) {
+	t.Valid, err = scanNull(&t.Value, src)
+
+	return err
+}
+
+func (t QFloat64) qValue() (driver.Value, error) {
+	return nullValue(t.Value, t.Valid)
+}
+
 func (t QFloat64) String() string {
 	return fmt.Sprintf("%f", t.Value)
 }
 
+// Deprecated: use QNull[string]
 type QString struct {
 	Value string
 	Valid bool
@@ -221,10 +410,22 @@ func (t *QString) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// Scan implements the sql.Scanner
+func (t *QString) Scan(src any) (err error) {
+	t.Valid, err = scanNull(&t.Value, src)
+
+	return err
+}
+
+func (t QString) qValue() (driver.Value, error) {
+	return nullValue(t.Value, t.Valid)
+}
+
 func (t QString) String() string {
 	return t.Value
 }
 
+// Deprecated: use QNull[[]string]
 type QStrings struct {
 	Value []string
 	Valid bool
@@ -286,10 +487,22 @@ func (t *QStrings) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// Scan implements the sql.Scanner
+func (t *QStrings) Scan(src any) (err error) {
+	t.Valid, err = scanNull(&t.Value, src)
+
+	return err
+}
+
+func (t QStrings) qValue() (driver.Value, error) {
+	return nullValue(t.Value, t.Valid)
+}
+
 func (t QStrings) String() string {
 	return fmt.Sprintf("%v", t.Value)
 }
 
+// Deprecated: use QNull[time.Time]
 type QTime struct {
 	Value time.Time
 	Valid bool
@@ -350,6 +563,17 @@ func (t *QTime) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// Scan implements the sql.Scanner
+func (t *QTime) Scan(src any) (err error) {
+	t.Valid, err = scanNull(&t.Value, src)
+
+	return err
+}
+
+func (t QTime) qValue() (driver.Value, error) {
+	return nullValue(t.Value, t.Valid)
+}
+
 func (t QTime) String() string {
 	return t.Value.Format(ConfigParseDateTimeFormat)
 }
@@ -0,0 +1,99 @@
+package dataq
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type qnullPerson struct {
+	ID      int64                 `COL:"ID" TABLE:"Person" INDEX:""`
+	Name    QNull[string]         `COL:"NAME"`
+	Age     QNull[int]            `COL:"AGE"`
+	Tags    QNull[[]string]       `COL:"TAGS"`
+	Created QNull[time.Time]      `COL:"CREATED"`
+	Extra   QNull[map[string]int] `COL:"EXTRA"`
+	Legacy  QInt                  `COL:"LEGACY"`
+}
+
+func TestQNull(t *testing.T) {
+	var age QNull[int]
+	if err := age.Scan([]byte("18")); err != nil || !age.Valid || age.V != 18 {
+		t.Fatalf("got %#v, %v", age, err)
+	}
+	if err := age.Scan(int64(20)); err != nil || age.V != 20 {
+		t.Fatalf("got %#v, %v", age, err)
+	}
+	if v, _ := age.Value(); v != int64(20) {
+		t.Fatalf("got %#v", v)
+	}
+	if err := age.Scan(nil); err != nil || age.Valid {
+		t.Fatalf("got %#v, %v", age, err)
+	}
+	if v, _ := age.Value(); v != nil {
+		t.Fatalf("got %#v", v)
+	}
+
+	var tags QNull[[]string]
+	if err := tags.Scan([]byte(`["a","b"]`)); err != nil || len(tags.V) != 2 {
+		t.Fatalf("got %#v, %v", tags, err)
+	}
+	if v, _ := tags.Value(); string(v.([]byte)) != `["a","b"]` {
+		t.Fatalf("got %#v", v)
+	}
+
+	var created QNull[time.Time]
+	if err := created.Scan("2023-01-02 03:04:05"); err != nil || created.V.Year() != 2023 {
+		t.Fatalf("got %#v, %v", created, err)
+	}
+
+	var name QNull[string]
+	if d, _ := json.Marshal(name); string(d) != "null" {
+		t.Fatalf("got %s", d)
+	}
+	if err := json.Unmarshal([]byte(`"Mike"`), &name); err != nil || !name.Valid || name.V != "Mike" {
+		t.Fatalf("got %#v, %v", name, err)
+	}
+	if d, _ := json.Marshal(name); string(d) != `"Mike"` {
+		t.Fatalf("got %s", d)
+	}
+	if err := json.Unmarshal([]byte(`null`), &name); err != nil || name.Valid {
+		t.Fatalf("got %#v, %v", name, err)
+	}
+
+	var _ driver.Valuer = QNull[int]{}
+}
+
+func TestQNullUpdate(t *testing.T) {
+	per := qnullPerson{ID: 1}
+	per.Age.Set(0)
+	per.Legacy.Set(0)
+
+	_s, _ := analyseStruct(&per, nil)
+	query, err := _s.composeUpdateSQL(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(query, "`AGE`=?") || !strings.Contains(query, "`LEGACY`=?") {
+		t.Fatalf("got %s", query)
+	}
+	for _, col := range []string{"NAME", "TAGS", "CREATED", "EXTRA"} {
+		if strings.Contains(query, col) {
+			t.Fatalf("the invalid %s must be skipped: %s", col, query)
+		}
+	}
+
+	stat := &QStat{dbc: &QData{}}
+	stat.SetModel(&qnullPerson{})
+	row, err := stat.decodeRow([]sql.RawBytes{[]byte("1"), nil, []byte("3"), []byte(`["a"]`), nil, []byte(`{"a":1}`), nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := row.Interface().(qnullPerson)
+	if got.Name.Valid || !got.Age.Valid || got.Age.V != 3 || got.Tags.V[0] != "a" || got.Created.Valid || got.Extra.V["a"] != 1 || got.Legacy.Valid {
+		t.Fatalf("got %#v", got)
+	}
+}